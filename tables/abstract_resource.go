@@ -1,14 +1,20 @@
 package tables
 
 import (
+	"context"
 	"math"
 	"net/http"
+	"net/url"
+	"reflect"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/humweb/go-tables/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 type AbstractResource struct {
@@ -21,38 +27,168 @@ type AbstractResource struct {
 	TableRequest    *TableRequest
 	HasGlobalSearch bool
 	DefaultPerPage  int
+	PaginationMode  PaginationMode
+	PrimaryKey      string
+	ArraySortLimit  int
+	QueryTimeout    time.Duration
+	ExportBatchSize int
+	BulkActions     []*BulkAction
+	BulkActionLimit int
+
+	// ResolveActionLinks resolves action field links per record on the server
+	ResolveActionLinks bool
+	// SelectVisible selects only the columns of visible fields and the keys the
+	// table needs instead of SELECT *
+	SelectVisible bool
+
+	// SearchMode selects substring or tokenized matching of search values
+	SearchMode SearchMode
+	// FullTextSearch replaces WithGlobalSearch with PostgreSQL full text matching when set
+	FullTextSearch *FullTextSearch
+
+	// relationColumns maps the relation attributes joined for the request to their column
+	relationColumns map[string]string
 }
 
 type Response map[string]any
 
 type TableProps struct {
-	Sort    string             `json:"sort"`
+	Sort    []string           `json:"sort"`
 	Page    int                `json:"page"`
 	PerPage int                `json:"perPage"`
 	Columns []*Field           `json:"columns"`
 	Search  map[string]*Search `json:"search"`
 	Filters []*Filter          `json:"filters"`
+
+	BulkActions []*BulkAction `json:"bulkActions,omitempty"`
 }
 
+// ToResponse builds the inertia-vue-table response for a page of records,
+// it is safe to call without a Request or TableRequest
 func (r *AbstractResource) ToResponse(paged *Pagination) Response {
+	if paged == nil {
+		paged = &Pagination{}
+	}
+
+	resp := Response{
+		"records":    paged.Rows,
+		"tableProps": r.tableProps(paged),
+		"pagination": paged.summary(),
+	}
+	if r.hasRowActions() {
+		resp["rowActions"] = r.resolveRowActions(paged.Rows)
+	}
+	return resp
+}
+
+// Base returns the AbstractResource, it lets resources embedding it satisfy TableResource
+func (r *AbstractResource) Base() *AbstractResource {
+	return r
+}
+
+// tableProps builds the table state sent to inertia-vue-table
+func (r *AbstractResource) tableProps(paged *Pagination) TableProps {
 	r.FlagVisibility()
 
-	return Response{
-		"records": paged.Rows,
-		"tableProps": TableProps{
-			Sort:    utils.DefaultString(r.Request.URL.Query().Get("sort"), "id"),
-			Page:    paged.Page,
-			PerPage: paged.Limit,
-			Columns: r.Fields,
-			Search:  r.collectFieldSearches(),
-			Filters: r.Filters,
-		},
-		"pagination": Pagination{
-			Limit:      paged.Limit,
-			Page:       paged.Page,
-			TotalPages: paged.TotalPages,
-			TotalRows:  paged.TotalRows,
-		},
+	var sort string
+	if r.Request != nil {
+		sort = r.Request.URL.Query().Get("sort")
+	}
+
+	return TableProps{
+		Sort:    utils.SortKeys(utils.DefaultString(sort, "id")),
+		Page:    paged.Page,
+		PerPage: paged.Limit,
+		Columns: r.columns(),
+		Search:  r.collectFieldSearches(),
+		Filters: r.allowedFilters(),
+
+		BulkActions: r.allowedBulkActions(),
+	}
+}
+
+// columns returns the fields the request may see, masked fields can't be sorted or searched
+// and action lists are limited to the actions the request may use. Changed fields are
+// copied so the resource's field definitions are left untouched
+func (r *AbstractResource) columns() []*Field {
+	columns := make([]*Field, 0, len(r.Fields))
+	for _, f := range r.Fields {
+		switch {
+		case f.denied(r.Request):
+			continue
+		case f.masked(r.Request):
+			field := *f
+			field.Sortable, field.Searchable = false, false
+			columns = append(columns, &field)
+		case f.Actions != nil:
+			field := *f
+			field.Actions = make([]*ActionItems, 0, len(f.Actions))
+			for _, a := range f.Actions {
+				if a.allowed(r.Request) {
+					field.Actions = append(field.Actions, a)
+				}
+			}
+			columns = append(columns, &field)
+		default:
+			columns = append(columns, f)
+		}
+	}
+	return columns
+}
+
+// restricted reports whether the attribute belongs to a field denied or masked for the request
+func (r *AbstractResource) restricted(attribute string) bool {
+	return slices.ContainsFunc(r.Fields, func(f *Field) bool {
+		return f.Attribute == attribute && !f.allowed(r.Request)
+	})
+}
+
+// allowedFilters returns the filters the request may use, filters on restricted fields are left out
+func (r *AbstractResource) allowedFilters() []*Filter {
+	filters := make([]*Filter, 0, len(r.Filters))
+	for _, f := range r.Filters {
+		if !r.restricted(f.Field) {
+			filters = append(filters, f)
+		}
+	}
+	return filters
+}
+
+// deniedColumns returns the columns of fields dropped for the request, relation attributes
+// aren't columns of the model and are cleared by maskRows instead
+func (r *AbstractResource) deniedColumns() []string {
+	var columns []string
+	for _, f := range r.Fields {
+		if f.Actions == nil && f.denied(r.Request) && !strings.Contains(f.Attribute, ".") {
+			columns = append(columns, f.Attribute)
+		}
+	}
+	return columns
+}
+
+// maskRows masks the values of masked fields and clears denied fields in place, denied
+// relation attributes such as "client.title" are still loaded when their relation is preloaded
+func (r *AbstractResource) maskRows(rows any) {
+	var fields []*Field
+	for _, f := range r.Fields {
+		if f.Actions == nil && !f.allowed(r.Request) {
+			fields = append(fields, f)
+		}
+	}
+
+	v := reflect.ValueOf(rows)
+	if len(fields) == 0 || v.Kind() != reflect.Slice {
+		return
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		for _, f := range fields {
+			mask := f.Mask
+			if mask == nil {
+				mask = clearValue
+			}
+			maskAttribute(v.Index(i), f.Attribute, mask)
+		}
 	}
 }
 
@@ -64,9 +200,14 @@ func (r *AbstractResource) collectFieldSearches() map[string]*Search {
 		ok       bool
 	)
 
+	var requested map[string]string
+	if r.TableRequest != nil {
+		requested = r.TableRequest.Search
+	}
+
 	// If global search enabled, we should always show it
 	if r.HasGlobalSearch {
-		val = r.TableRequest.Search["global"]
+		val = requested["global"]
 		searches["global"] = &Search{
 			Label:   "Search..",
 			Field:   "global",
@@ -77,8 +218,8 @@ func (r *AbstractResource) collectFieldSearches() map[string]*Search {
 
 	// Handle Searchable fields
 	for _, field := range r.Fields {
-		if field.Searchable {
-			val, ok = r.TableRequest.Search[field.Attribute]
+		if field.Searchable && field.allowed(r.Request) {
+			val, ok = requested[field.Attribute]
 			searches[field.Attribute] = &Search{
 				Label:   field.Name,
 				Field:   field.Attribute,
@@ -93,6 +234,10 @@ func (r *AbstractResource) collectFieldSearches() map[string]*Search {
 
 // FlagVisibility applies visibility flag to field the attributes
 func (r *AbstractResource) FlagVisibility() {
+	if r.Request == nil {
+		return
+	}
+
 	fields := r.Request.URL.Query().Get("hidden")
 	fieldS := strings.Split(fields, ",")
 
@@ -108,20 +253,186 @@ func (r *AbstractResource) ApplySearch(db *gorm.DB, field, value string) {
 	if v, err := strconv.Atoi(value); err == nil {
 		db.Where(field+" = ?", v)
 	} else {
-		db.Where(field+" ILIKE ?", "%"+value+"%")
+		db.Where(DialectOf(db).Contains(field), "%"+value+"%")
 	}
 }
 
+// WithGlobalSearch is the default global search, it matches the value against the searchable
+// fields the request may see in a grouped OR. Integers match numeric columns exactly and other
+// values match text columns case insensitively. Fields without a column in the model's schema
+// and count fields are skipped. Resources override it for custom global search
+func (r *AbstractResource) WithGlobalSearch(db *gorm.DB, val string) {
+	var sch *schema.Schema
+	if err := db.Statement.Parse(db.Statement.Model); err == nil {
+		sch = db.Statement.Schema
+	}
+	n, err := strconv.Atoi(val)
+	isInt := err == nil
+
+	var (
+		conds []string
+		args  []any
+	)
+	for _, f := range r.Fields {
+		if !f.Searchable || !f.allowed(r.Request) || f.CountRelation != "" || strings.Contains(f.Attribute, ".") {
+			continue
+		}
+
+		// Attributes missing from the schema, such as computed values, have no column
+		var dataType schema.DataType
+		if sch != nil {
+			sf := sch.LookUpField(f.Attribute)
+			if sf == nil || sf.DBName == "" {
+				continue
+			}
+			dataType = sf.DataType
+		}
+
+		column := f.Attribute
+		switch dataType {
+		case schema.Int, schema.Uint, schema.Float:
+			if isInt {
+				conds = append(conds, column+" = ?")
+				args = append(args, n)
+			}
+		case schema.String, "":
+			if isInt && dataType == "" {
+				conds = append(conds, column+" = ?")
+				args = append(args, n)
+			} else {
+				conds = append(conds, DialectOf(db).Contains(column))
+				args = append(args, "%"+val+"%")
+			}
+		}
+	}
+
+	if len(conds) == 0 {
+		// Nothing can match the value
+		db.Where("1 = 0")
+		return
+	}
+	db.Where("("+strings.Join(conds, " OR ")+")", args...)
+}
+
 // Paginate this is the main function for our resource
 // It applies filters and search criteria and paginates
-// Pagination uses a "Length aware" approach
+// Pagination uses a "Length aware" approach unless another PaginationMode is set
+//
+// When an error is returned the Response is always nil. Errors match ErrValidation
+// for bad request parameters, ErrQuery for database failures (ErrCount when
+// counting failed) and ErrArraySortLimit when too many rows are sorted in memory,
+// errors returned by custom filter queries are passed through unchanged
+//
+// Queries run with the request's context, see PaginateContext. A resource without a
+// request is paginated as if the query string was empty
 func (r *AbstractResource) Paginate(resource ITable, model any) (Response, error) {
-	r.TableRequest = &TableRequest{}
+	ctx := context.Background()
+	if r.Request != nil {
+		ctx = r.Request.Context()
+	}
+	return r.PaginateContext(ctx, resource, model)
+}
+
+// PaginateContext is Paginate with an explicit context for the count and select queries,
+// QueryTimeout is applied on top of the given context when set
+func (r *AbstractResource) PaginateContext(ctx context.Context, resource ITable, model any) (Response, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	p, err := r.paginate(ctx, resource, model)
+	if err != nil {
+		return nil, err
+	}
+	// Row actions are resolved before masking so links use the real values
+	resp := r.ToResponse(p)
+	r.maskRows(p.Rows)
+	return resp, nil
+}
+
+// withTimeout applies QueryTimeout to the context when set
+func (r *AbstractResource) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.QueryTimeout > 0 {
+		return context.WithTimeout(ctx, r.QueryTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// paginate builds and runs the queries for Paginate
+func (r *AbstractResource) paginate(ctx context.Context, resource ITable, model any) (*Pagination, error) {
+	q, p, err := r.prepareQuery(ctx, resource, model)
+	if err != nil {
+		return nil, err
+	}
 
 	var totalRows int64
 
+	arraySort := r.arraySortColumns(p.GetSort())
+
+	switch r.PaginationMode {
+	case CursorPagination:
+		if arraySort != nil {
+			return nil, &ValidationError{Param: "sort", Key: r.TableRequest.Sort, Reason: "array sort is not supported with cursor pagination"}
+		}
+		return r.cursorPaginate(q, model, p)
+	case SimplePagination:
+		if arraySort != nil {
+			r.eagerLoad(q)
+			return r.arrayPaginate(q, model, p, arraySort)
+		}
+		return r.simplePaginate(q, model, p)
+	}
+
+	// -- Get records count
+	if err := q.Count(&totalRows).Error; err != nil {
+		return nil, newQueryError(q, "count", err)
+	}
+	p.TotalRows = totalRows
+
+	// Eager load relationships
+	r.eagerLoad(q)
+
+	// Start pagination
+	totalPages := int(math.Ceil(float64(totalRows) / float64(p.Limit)))
+	p.TotalPages = totalPages
+
+	if arraySort != nil {
+		return r.arrayPaginate(q, model, p, arraySort)
+	}
+
+	// add pagination offset and order
+	q.Offset(p.GetOffset()).
+		Limit(p.GetLimit())
+	r.orderBy(q, p.GetSort())
+
+	// Get results
+	if err := q.Find(&model).Error; err != nil {
+		return nil, newQueryError(q, "select", err)
+	}
+	p.Rows = model
+
+	return p, nil
+}
+
+// requestURL returns the request URL, resources without a request use an empty query
+func (r *AbstractResource) requestURL() *url.URL {
+	if r.Request == nil || r.Request.URL == nil {
+		return &url.URL{}
+	}
+	return r.Request.URL
+}
+
+// prepareQuery parses and validates the request and builds the filtered query
+// shared by pagination and exports
+func (r *AbstractResource) prepareQuery(ctx context.Context, resource ITable, model any) (*gorm.DB, *Pagination, error) {
+	r.TableRequest = &TableRequest{}
+
 	// Parse filters and search from request
-	r.TableRequest.Fill(r.Request.URL)
+	r.TableRequest.Fill(r.requestURL())
+
+	// Reject any keys not whitelisted by the resource
+	if err := r.validateRequest(); err != nil {
+		return nil, nil, err
+	}
 
 	if r.TableRequest.PerPage == 25 && r.DefaultPerPage != 0 {
 		r.TableRequest.PerPage = r.DefaultPerPage
@@ -132,67 +443,239 @@ func (r *AbstractResource) Paginate(resource ITable, model any) (Response, error
 		Limit: r.TableRequest.PerPage,
 		Page:  r.TableRequest.Page,
 		Sort:  r.TableRequest.Sort,
+		Mode:  r.PaginationMode,
 	}
 
 	// -- Start Query
-	q := r.DB.Model(model)
+	q := r.DB.WithContext(ctx).Model(model)
+
+	// Add count columns and the relation columns of dotted sort and search attributes
+	var derived derivedColumns
+	if err := r.withCounts(q, p.GetSort(), &derived); err != nil {
+		return nil, nil, err
+	}
+	if err := r.joinRelations(q, p.GetSort(), &derived); err != nil {
+		return nil, nil, err
+	}
+	derived.wrap(q)
+
+	// Select only the columns needed, or leave out the columns the request may not see
+	if r.SelectVisible {
+		q.Select(r.selectColumns(q, p.GetSort()))
+	} else if omit := r.deniedColumns(); omit != nil {
+		q.Omit(omit...)
+	}
 
 	// Apply filters to query
-	r.applySearch(resource, q)
-	r.applyFilters(r.TableRequest.Filters, q)
+	if err := r.applySearch(resource, q); err != nil {
+		return nil, nil, err
+	}
+	if err := r.applyFilters(q); err != nil {
+		return nil, nil, err
+	}
 
 	resource.ApplyFilter(q)
 
-	// -- Get records count
-	q.Count(&totalRows)
-	p.TotalRows = totalRows
-
-	//q = r.DB.Model(model)
+	return q, p, nil
+}
 
-	// Eager load relationships
+// simplePaginate fetches a page of rows without counting, an extra row is
+// fetched to find out if there is another page
+func (r *AbstractResource) simplePaginate(q *gorm.DB, model any, p *Pagination) (*Pagination, error) {
 	r.eagerLoad(q)
 
-	// Start pagination
-	totalPages := int(math.Ceil(float64(totalRows) / float64(p.Limit)))
-	p.TotalPages = totalPages
-
-	// add pagination offset and order
 	q.Offset(p.GetOffset()).
-		Limit(p.GetLimit()).
-		Order(p.GetSort())
+		Limit(p.GetLimit() + 1)
+	r.orderBy(q, p.GetSort())
 
-	// Get results
-	err := q.Find(&model).Error
-	if err == nil {
-		p.Rows = model
+	if err := q.Find(&model).Error; err != nil {
+		return nil, newQueryError(q, "select", err)
+	}
+
+	rows, hasMore := trimRows(model, p.GetLimit())
+	p.Rows = rows.Interface()
+	p.HasMore = hasMore
+
+	return p, nil
+}
+
+// orderBy applies the page sort with the fields' NULL ordering, full text matches are ranked
+// first when ranking is enabled and the request has no sort, the sort then breaks ties
+func (r *AbstractResource) orderBy(q *gorm.DB, sort string) {
+	sort = r.sortTerms(q, sort)
+
+	s := r.FullTextSearch
+	value := r.TableRequest.Search["global"]
+	if s == nil || !s.Rank || value == "" || r.requestURL().Query().Get("sort") != "" {
+		q.Order(sort)
+		return
+	}
+
+	document := r.document()
+	if document == "" {
+		q.Order(sort)
+		return
+	}
+	q.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:  "ts_rank(" + document + ", " + s.query() + ") DESC, " + sort,
+		Vars: []any{value},
+	}})
+}
+
+// sortTerms rewrites the sort columns of joined relations to their column and the sort
+// columns of fields with a NULL ordering for the database's dialect
+func (r *AbstractResource) sortTerms(q *gorm.DB, sort string) string {
+	cols := parseSort(sort)
+	terms := make([]string, len(cols))
+	changed := false
+
+	for i, c := range cols {
+		column := r.columnOf(c.Column)
+		changed = changed || column != c.Column
+		if f := r.field(c.Column); f != nil && f.Nulls != NullsDefault {
+			terms[i] = DialectOf(q).OrderNulls(column, c.Desc, f.Nulls == NullsFirst)
+			changed = true
+		} else {
+			terms[i] = orderTerm(column, c.Desc)
+		}
 	}
 
-	return r.ToResponse(p), err
+	if !changed {
+		return sort
+	}
+	return strings.Join(terms, ", ")
+}
+
+// validateRequest ensures sort, search and filter keys reference sortable fields,
+// searchable fields and registered filters before they reach the query. Fields the
+// request may not see can't be sorted, searched or filtered on, filters on them would
+// reveal denied and masked values
+func (r *AbstractResource) validateRequest() error {
+	for _, key := range utils.SortKeys(r.requestURL().Query().Get("sort")) {
+		key = strings.TrimPrefix(key, "-")
+		if !slices.ContainsFunc(r.Fields, func(f *Field) bool {
+			return f.Sortable && f.Attribute == key && f.allowed(r.Request)
+		}) {
+			return &ValidationError{Param: "sort", Key: key}
+		}
+	}
+
+	for key := range r.TableRequest.Search {
+		if key == "global" && r.HasGlobalSearch {
+			continue
+		}
+		if !slices.ContainsFunc(r.Fields, func(f *Field) bool {
+			return f.Searchable && f.Attribute == key && f.allowed(r.Request)
+		}) {
+			return &ValidationError{Param: "search", Key: key}
+		}
+	}
+
+	for key := range r.TableRequest.Filters {
+		if !slices.ContainsFunc(r.Filters, func(f *Filter) bool {
+			return f.Field == key && !r.restricted(key)
+		}) {
+			return &ValidationError{Param: "filter", Key: key}
+		}
+	}
+
+	for key, ops := range r.TableRequest.FilterOps {
+		i := slices.IndexFunc(r.Filters, func(f *Filter) bool {
+			return f.Field == key && !r.restricted(key)
+		})
+		if i < 0 {
+			return &ValidationError{Param: "filter", Key: key}
+		}
+		for op := range ops {
+			if !r.Filters[i].Allows(op) {
+				return &ValidationError{Param: "filter", Key: key + "[" + string(op) + "]", Reason: "operator not allowed"}
+			}
+		}
+	}
+
+	return nil
 }
 
 // applyFilters applies filter criteria to the database query
-func (r *AbstractResource) applyFilters(filters map[string]string, q *gorm.DB) {
+func (r *AbstractResource) applyFilters(q *gorm.DB) error {
 	for _, f := range r.Filters {
-		if val, ok := filters[f.Field]; ok {
+		if val, ok := r.TableRequest.Filters[f.Field]; ok {
 			f.Value = val
-			f.ApplyQuery(q)
+			if err := f.ApplyQuery(q); err != nil {
+				return err
+			}
+		}
+
+		ops := r.TableRequest.FilterOps[f.Field]
+		keys := make([]Operator, 0, len(ops))
+		for op := range ops {
+			keys = append(keys, op)
+		}
+		slices.Sort(keys)
+
+		for _, op := range keys {
+			if f.Values == nil {
+				f.Values = make(map[string]string)
+			}
+			f.Values[string(op)] = ops[op]
+			if err := f.ApplyOperator(q, op, ops[op]); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
 // applySearch applies search criteria to the database query
-func (r *AbstractResource) applySearch(resource ITable, q *gorm.DB) {
+func (r *AbstractResource) applySearch(resource ITable, q *gorm.DB) error {
 
 	for field, value := range r.TableRequest.Search {
-		if field == "global" {
-			resource.WithGlobalSearch(q, value)
+		var search func(db *gorm.DB, value string)
+
+		switch {
+		case field != "global":
+			column := r.columnOf(field)
+			search = func(db *gorm.DB, value string) {
+				r.ApplySearch(db, column, value)
+			}
+		case r.FullTextSearch != nil:
+			// Full text queries handle phrases and exclusions themselves
+			if err := r.applyFullTextSearch(q, value); err != nil {
+				return err
+			}
+			continue
+		default:
+			search = resource.WithGlobalSearch
+		}
+
+		if r.SearchMode == TokenizedSearch {
+			r.applyTerms(q, value, search)
 		} else {
-			r.ApplySearch(q, field, value)
+			search(q, value)
 		}
 	}
+	return nil
 }
 
-// applySearch applies search criteria to the database query
+// applyTerms applies a search callback to every term of a tokenized search value, all terms
+// must match and excluded terms must not match. Excluded terms are built by the same callback
+// and negated, criteria on NULL columns count as not matching
+func (r *AbstractResource) applyTerms(q *gorm.DB, value string, search func(db *gorm.DB, value string)) {
+	for _, t := range ParseSearchTerms(value) {
+		if !t.Exclude {
+			search(q, t.Text)
+			continue
+		}
+
+		sub := q.Session(&gorm.Session{NewDB: true}).Model(q.Statement.Model)
+		search(sub, t.Text)
+		if where, ok := sub.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
+			q.Where("CASE WHEN (?) THEN 1 ELSE 0 END = 0", clause.AndConditions{Exprs: where.Exprs})
+		}
+	}
+}
+
+// eagerLoad preloads the configured relationships and the relations of dotted field attributes
 func (r *AbstractResource) eagerLoad(q *gorm.DB) {
 	for _, rel := range r.Preloads {
 		if rel.Extra == nil {
@@ -201,4 +684,7 @@ func (r *AbstractResource) eagerLoad(q *gorm.DB) {
 			q.Preload(rel.Name, rel.Extra)
 		}
 	}
+	for _, name := range r.relationPreloads(q) {
+		q.Preload(name)
+	}
 }
@@ -1,7 +1,136 @@
 package tables
 
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
 type ActionItems struct {
-	Label  string   `json:"label"`
-	Link   string   `json:"link"`
-	Params []string `json:"params"`
+	Label   string   `json:"label"`
+	Link    string   `json:"link"`
+	Params  []string `json:"params"`
+	Confirm string   `json:"confirm,omitempty"`
+	Method  string   `json:"method,omitempty"`
+	Icon    string   `json:"icon,omitempty"`
+
+	// Authorize hides the action for the whole request when it returns false
+	Authorize func(r *http.Request) bool `json:"-"`
+	// AuthorizeRow hides the action for records it returns false for
+	AuthorizeRow func(r *http.Request, record any) bool `json:"-"`
+}
+
+// ResolvedAction is an action link resolved for a single record
+type ResolvedAction struct {
+	Label   string `json:"label"`
+	Link    string `json:"link"`
+	Confirm string `json:"confirm,omitempty"`
+	Method  string `json:"method,omitempty"`
+	Icon    string `json:"icon,omitempty"`
+}
+
+// RowActions holds the resolved actions of a record keyed by action field attribute
+type RowActions map[string][]ResolvedAction
+
+var linkParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Resolve substitutes the link params with values read from the record, params may be
+// nested paths such as "client.id". Params default to the placeholders in the link and
+// placeholders without a value are left in place. Values are path escaped, or query
+// escaped after the "?" so they can't add query parameters
+func (a *ActionItems) Resolve(record any) string {
+	path, query, hasQuery := strings.Cut(a.Link, "?")
+	for _, param := range a.params() {
+		if v := pathValue(record, param); v != nil {
+			value := exportString(v)
+			path = strings.ReplaceAll(path, "{"+param+"}", url.PathEscape(value))
+			query = strings.ReplaceAll(query, "{"+param+"}", url.QueryEscape(value))
+		}
+	}
+	if !hasQuery {
+		return path
+	}
+	return path + "?" + query
+}
+
+// params returns the action params, defaulting to the placeholders in the link
+func (a *ActionItems) params() []string {
+	if len(a.Params) > 0 {
+		return a.Params
+	}
+
+	var params []string
+	for _, m := range linkParam.FindAllStringSubmatch(a.Link, -1) {
+		params = append(params, m[1])
+	}
+	return params
+}
+
+// allowed reports whether the request may use the action
+func (a *ActionItems) allowed(req *http.Request) bool {
+	return a.Authorize == nil || (req != nil && a.Authorize(req))
+}
+
+// allowedRow reports whether the request may use the action on the record
+func (a *ActionItems) allowedRow(req *http.Request, record any) bool {
+	return a.AuthorizeRow == nil || (req != nil && a.AuthorizeRow(req, record))
+}
+
+// hasRowActions reports whether per record actions should be sent, either because
+// links are resolved on the server or because actions depend on the record
+func (r *AbstractResource) hasRowActions() bool {
+	if r.ResolveActionLinks {
+		return true
+	}
+	for _, f := range r.Fields {
+		for _, a := range f.Actions {
+			if a.AuthorizeRow != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveRowActions returns the actions the request may use on every record, in record
+// order. Links are only resolved when ResolveActionLinks is set
+func (r *AbstractResource) resolveRowActions(rows any) []RowActions {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+
+	resolved := make([]RowActions, v.Len())
+	for i := range resolved {
+		record := v.Index(i).Interface()
+		resolved[i] = RowActions{}
+
+		for _, f := range r.Fields {
+			if f.Actions == nil {
+				continue
+			}
+			actions := make([]ResolvedAction, 0, len(f.Actions))
+			for _, a := range f.Actions {
+				if !a.allowed(r.Request) || !a.allowedRow(r.Request, record) {
+					continue
+				}
+
+				link := a.Link
+				if r.ResolveActionLinks {
+					link = a.Resolve(record)
+				}
+				actions = append(actions, ResolvedAction{
+					Label:   a.Label,
+					Link:    link,
+					Confirm: a.Confirm,
+					Method:  a.Method,
+					Icon:    a.Icon,
+				})
+			}
+			resolved[i][f.Attribute] = actions
+		}
+	}
+	return resolved
 }
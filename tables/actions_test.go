@@ -0,0 +1,141 @@
+package tables
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestActionResolve(t *testing.T) {
+	is := assert.New(t)
+
+	user := UserPrivate{ID: 3, Username: "a/b c", Client: Client{ID: 9}}
+
+	action := &ActionItems{Label: "Users", Link: "/clients/{client.id}/users/{id}", Params: []string{"client.id", "id"}}
+	is.Equal("/clients/9/users/3", action.Resolve(user))
+
+	action = &ActionItems{Label: "Profile", Link: "/profiles/{username}"}
+	is.Equal("/profiles/a%2Fb%20c", action.Resolve(&user))
+
+	action = &ActionItems{Label: "Sites", Link: "/sites/{site.id}"}
+	is.Equal("/sites/{site.id}", action.Resolve(user))
+
+	// Query string values can't add parameters
+	user.Username = "a&admin=1+x;y"
+	action = &ActionItems{Label: "Search", Link: "/users/{username}?name={username}&id={id}"}
+	is.Equal("/users/a&admin=1+x%3By?name=a%26admin%3D1%2Bx%3By&id=3", action.Resolve(user))
+}
+
+func TestResolveActionLinks(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+	res.ResolveActionLinks = true
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+
+	rowActions := resp["rowActions"].([]RowActions)
+	is.Len(rowActions, 2)
+	is.Equal([]ResolvedAction{
+		{Label: "Users", Link: "/clients/1/users"},
+		{Label: "Sites", Link: "/clients/1/Sites"},
+	}, rowActions[0]["filters"])
+	is.Equal("/clients/2/users", rowActions[1]["filters"][0].Link)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestResolveActionLinksMasked(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+	res.ResolveActionLinks = true
+	res.Fields[0] = NewField("ID", WithAuthorize(func(r *http.Request) bool {
+		return false
+	}), WithMask(func(v any) any {
+		return nil
+	}))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+
+	// Links are resolved from the values before they are masked
+	is.Equal(uint(0), resp["records"].([]UserPrivate)[0].ID)
+	is.Equal("/clients/7/users", resp["rowActions"].([]RowActions)[0]["filters"][0].Link)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	page, err := Paginate[UserPrivate](context.Background(), res)
+	is.Nil(err)
+	is.Equal(uint(0), page.Records[0].ID)
+	is.Equal("/clients/7/users", page.RowActions[0]["filters"][0].Link)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestActionAuthorization(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+
+	sites := res.Fields[len(res.Fields)-1].Actions[1]
+	sites.Authorize = func(r *http.Request) bool { return false }
+	users := res.Fields[len(res.Fields)-1].Actions[0]
+	users.Method = http.MethodDelete
+	users.Confirm = "Sure?"
+	users.AuthorizeRow = func(r *http.Request, record any) bool {
+		return record.(UserPrivate).ID != 2
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+
+	columns := resp["tableProps"].(TableProps).Columns
+	is.Equal([]*ActionItems{users}, columns[len(columns)-1].Actions)
+	is.Len(res.Fields[len(res.Fields)-1].Actions, 2)
+
+	rowActions := resp["rowActions"].([]RowActions)
+	is.Equal([]ResolvedAction{
+		{Label: "Users", Link: "/clients/{id}/users", Confirm: "Sure?", Method: http.MethodDelete},
+	}, rowActions[0]["filters"])
+	is.Empty(rowActions[1]["filters"])
+	is.Nil(mock.ExpectationsWereMet())
+}
@@ -0,0 +1,136 @@
+package tables
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultArraySortLimit is the maximum number of rows loaded for in memory sorting
+const DefaultArraySortLimit = 10000
+
+// arraySortColumns returns the active sort columns when any of them targets
+// a field sorted in memory
+func (r *AbstractResource) arraySortColumns(sort string) []sortColumn {
+	cols := parseSort(sort)
+	for _, c := range cols {
+		if f := r.field(c.Column); f != nil && f.HasArraySort {
+			return cols
+		}
+	}
+	return nil
+}
+
+// field returns the field with the given attribute
+func (r *AbstractResource) field(attribute string) *Field {
+	for _, f := range r.Fields {
+		if f.Attribute == attribute {
+			return f
+		}
+	}
+	return nil
+}
+
+// arraySortLimit returns the configured safety cap on rows sorted in memory
+func (r *AbstractResource) arraySortLimit() int {
+	if r.ArraySortLimit == 0 {
+		return DefaultArraySortLimit
+	}
+	return r.ArraySortLimit
+}
+
+// arrayPaginate loads every matching row, sorts them in memory and slices out the requested page
+func (r *AbstractResource) arrayPaginate(q *gorm.DB, model any, p *Pagination, cols []sortColumn) (*Pagination, error) {
+	limit := r.arraySortLimit()
+	if p.TotalRows > int64(limit) {
+		return nil, ErrArraySortLimit
+	}
+
+	if err := q.Limit(limit + 1).Order(r.primaryKey() + " ASC").Find(&model).Error; err != nil {
+		return nil, newQueryError(q, "select", err)
+	}
+
+	rows := reflect.ValueOf(model)
+	if rows.Len() > limit {
+		return nil, ErrArraySortLimit
+	}
+
+	sorted := make([]any, rows.Len())
+	for i := range sorted {
+		sorted[i] = rows.Index(i).Interface()
+	}
+	slices.SortStableFunc(sorted, func(a, b any) int {
+		for _, c := range cols {
+			var n int
+			if f := r.field(c.Column); f != nil && f.ArraySortFunc != nil {
+				n = f.ArraySortFunc(a, b)
+			} else {
+				n = compareValues(pathValue(a, c.Column), pathValue(b, c.Column))
+			}
+			if c.Desc {
+				n = -n
+			}
+			if n != 0 {
+				return n
+			}
+		}
+		return 0
+	})
+
+	start := min(p.GetOffset(), len(sorted))
+	end := min(start+p.GetLimit(), len(sorted))
+
+	page := reflect.MakeSlice(rows.Type(), end-start, end-start)
+	for i, row := range sorted[start:end] {
+		page.Index(i).Set(reflect.ValueOf(row))
+	}
+	p.Rows = page.Interface()
+	p.HasMore = end < len(sorted)
+
+	return p, nil
+}
+
+// compareValues orders two attribute values of the same type, nil values sort first
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			return at.Compare(bt)
+		}
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case av.CanInt() && bv.CanInt():
+		return cmp.Compare(av.Int(), bv.Int())
+	case av.CanUint() && bv.CanUint():
+		return cmp.Compare(av.Uint(), bv.Uint())
+	case av.CanFloat() && bv.CanFloat():
+		return cmp.Compare(av.Float(), bv.Float())
+	case av.Kind() == reflect.Bool && bv.Kind() == reflect.Bool:
+		return cmp.Compare(boolInt(av.Bool()), boolInt(bv.Bool()))
+	case av.Kind() == reflect.String && bv.Kind() == reflect.String:
+		return strings.Compare(av.String(), bv.String())
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
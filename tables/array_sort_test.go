@@ -0,0 +1,37 @@
+package tables
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCompareValues(t *testing.T) {
+	is := assert.New(t)
+	now := time.Now()
+
+	is.Equal(-1, compareValues(1, 2))
+	is.Equal(1, compareValues(uint(3), uint(2)))
+	is.Equal(0, compareValues(1.5, 1.5))
+	is.Equal(-1, compareValues("a", "b"))
+	is.Equal(-1, compareValues(false, true))
+	is.Equal(-1, compareValues(now, now.Add(time.Second)))
+	is.Equal(-1, compareValues(nil, "a"))
+	is.Equal(1, compareValues("a", nil))
+}
+
+func TestArraySortFunc(t *testing.T) {
+	is := assert.New(t)
+
+	byLength := func(a, b any) int {
+		return len(a.(UserPrivate).FirstName) - len(b.(UserPrivate).FirstName)
+	}
+	field := NewField("First name", WithArraySortFunc(byLength))
+
+	is.True(field.HasArraySort)
+	is.Equal(-1, field.ArraySortFunc(UserPrivate{FirstName: "ab"}, UserPrivate{FirstName: "abc"}))
+
+	r := &AbstractResource{Fields: []*Field{NewField("ID", WithSortable()), field}}
+	is.Nil(r.arraySortColumns("id ASC"))
+	is.Len(r.arraySortColumns("id ASC, first_name DESC"), 2)
+}
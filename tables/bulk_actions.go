@@ -0,0 +1,197 @@
+package tables
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/humweb/go-tables/utils"
+	"gorm.io/gorm"
+)
+
+// DefaultBulkActionLimit is the maximum number of records a bulk action is run on
+const DefaultBulkActionLimit = 10000
+
+// BulkActionHandler runs a bulk action against the selected record ids
+type BulkActionHandler func(ctx context.Context, db *gorm.DB, ids []any) error
+
+// BulkAction is a named action applied to many records at once
+type BulkAction struct {
+	Name    string `json:"name"`
+	Label   string `json:"label"`
+	Confirm string `json:"confirm,omitempty"`
+
+	Handler   BulkActionHandler        `json:"-"`
+	Authorize func(*http.Request) bool `json:"-"`
+}
+
+// BulkActionOpt is an optional function type to set bulk action attributes
+type BulkActionOpt func(*BulkAction)
+
+// NewBulkAction creates a new bulk action
+func NewBulkAction(label string, handler BulkActionHandler, opts ...BulkActionOpt) *BulkAction {
+	a := &BulkAction{
+		Name:    utils.Slug(label),
+		Label:   label,
+		Handler: handler,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// WithBulkName allows you to override the default generated action name
+func WithBulkName(name string) BulkActionOpt {
+	return func(a *BulkAction) {
+		a.Name = name
+	}
+}
+
+// WithBulkConfirm sets a confirmation message shown before running the action
+func WithBulkConfirm(text string) BulkActionOpt {
+	return func(a *BulkAction) {
+		a.Confirm = text
+	}
+}
+
+// WithBulkAuthorize restricts the action to requests the predicate allows
+func WithBulkAuthorize(fn func(*http.Request) bool) BulkActionOpt {
+	return func(a *BulkAction) {
+		a.Authorize = fn
+	}
+}
+
+// allowed reports whether the request may run the action
+func (a *BulkAction) allowed(req *http.Request) bool {
+	return a.Authorize == nil || (req != nil && a.Authorize(req))
+}
+
+// BulkActionRequest is the POSTed selection for a bulk action
+type BulkActionRequest struct {
+	Action string `json:"action"`
+	IDs    []any  `json:"ids"`
+	All    bool   `json:"all"`
+}
+
+// allowedBulkActions returns the bulk actions the request may run
+func (r *AbstractResource) allowedBulkActions() []*BulkAction {
+	var actions []*BulkAction
+	for _, a := range r.BulkActions {
+		if a.allowed(r.Request) {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// bulkActionLimit returns the configured cap on records selected for a bulk action
+func (r *AbstractResource) bulkActionLimit() int {
+	if r.BulkActionLimit == 0 {
+		return DefaultBulkActionLimit
+	}
+	return r.BulkActionLimit
+}
+
+// DispatchBulkAction runs the bulk action POSTed with the request. The body is either
+// JSON or a form with "action", "ids" (repeated or comma separated) and "all".
+//
+// Selected ids are resolved through the same search, filters and ApplyFilter scope as
+// Paginate using the request's query string, so ids outside the resource are dropped.
+// When "all" is set every record matching the current filters is selected, selections
+// over BulkActionLimit records return ErrBulkActionLimit
+func (r *AbstractResource) DispatchBulkAction(resource ITable, model any) error {
+	if r.Request == nil || r.Request.Method != http.MethodPost {
+		var method string
+		if r.Request != nil {
+			method = r.Request.Method
+		}
+		return &ValidationError{Param: "method", Key: method, Reason: "bulk actions must be POSTed"}
+	}
+	ctx := r.Request.Context()
+
+	br, err := parseBulkActionRequest(r.Request)
+	if err != nil {
+		return err
+	}
+
+	var action *BulkAction
+	for _, a := range r.BulkActions {
+		if a.Name == br.Action {
+			action = a
+			break
+		}
+	}
+	if action == nil {
+		return &ValidationError{Param: "action", Key: br.Action}
+	}
+	if !action.allowed(r.Request) {
+		return ErrForbidden
+	}
+	if !br.All && len(br.IDs) == 0 {
+		return &ValidationError{Param: "ids", Key: br.Action, Reason: "no records selected"}
+	}
+
+	q, _, err := r.prepareQuery(ctx, resource, model)
+	if err != nil {
+		return err
+	}
+
+	// Qualify the primary key in case a custom ApplyFilter joins other tables
+	pk := r.primaryKey()
+	if err = q.Statement.Parse(q.Statement.Model); err == nil {
+		pk = DialectOf(q).Quote(q.Statement.Schema.Table + "." + pk)
+	}
+	if !br.All {
+		q.Where(pk+" IN ?", br.IDs)
+	}
+
+	limit := r.bulkActionLimit()
+	var ids []any
+	if err = q.Limit(limit+1).Pluck(pk, &ids).Error; err != nil {
+		return newQueryError(q, "select", err)
+	}
+	if len(ids) == 0 {
+		return &ValidationError{Param: "ids", Key: br.Action, Reason: "no matching records"}
+	}
+	if len(ids) > limit {
+		return ErrBulkActionLimit
+	}
+
+	return action.Handler(ctx, r.DB.WithContext(ctx), ids)
+}
+
+// parseBulkActionRequest reads the bulk action selection from a JSON or form body
+func parseBulkActionRequest(req *http.Request) (*BulkActionRequest, error) {
+	br := &BulkActionRequest{}
+
+	if ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); ct == "application/json" {
+		d := json.NewDecoder(req.Body)
+		d.UseNumber()
+		if err := d.Decode(br); err != nil {
+			return nil, &ValidationError{Param: "body", Key: "json", Reason: err.Error()}
+		}
+		normalizeNumbers(br.IDs)
+		return br, nil
+	}
+
+	if err := req.ParseForm(); err != nil {
+		return nil, &ValidationError{Param: "body", Key: "form", Reason: err.Error()}
+	}
+
+	br.Action = req.PostForm.Get("action")
+	br.All, _ = strconv.ParseBool(req.PostForm.Get("all"))
+	for _, key := range []string{"ids", "ids[]"} {
+		for _, val := range req.PostForm[key] {
+			for _, id := range strings.Split(val, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					br.IDs = append(br.IDs, id)
+				}
+			}
+		}
+	}
+	return br, nil
+}
@@ -0,0 +1,156 @@
+package tables
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newBulkResource(db *gorm.DB, req *http.Request, ran *[]any) *UserResource {
+	res := NewUserResource(db, req)
+	res.BulkActions = []*BulkAction{
+		NewBulkAction("Archive", func(ctx context.Context, db *gorm.DB, ids []any) error {
+			*ran = ids
+			return nil
+		}, WithBulkConfirm("Archive the selected users?")),
+		NewBulkAction("Delete", func(ctx context.Context, db *gorm.DB, ids []any) error {
+			return errors.New("should not run")
+		}, WithBulkAuthorize(func(r *http.Request) bool {
+			return r.Header.Get("X-Role") == "admin"
+		})),
+	}
+	return res
+}
+
+func TestDispatchBulkActionJSON(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodPost, "/users/bulk?filters[id][gte]=1", strings.NewReader(`{"action":"archive","ids":[1,2,99]}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	var ran []any
+	res := newBulkResource(db, request, &ran)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "users"."id" FROM "users" WHERE id >= $1 AND "users"."id" IN ($2,$3,$4) LIMIT $5`)).
+		WithArgs(1, 1, 2, 99, DefaultBulkActionLimit+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+
+	var aryUsers []UserPrivate
+	is.Nil(res.DispatchBulkAction(res, aryUsers))
+	is.Equal([]any{int64(1), int64(2)}, ran)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestDispatchBulkActionAll(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodPost, "/users/bulk?search[last_name]=bar", strings.NewReader("action=archive&all=1"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var ran []any
+	res := newBulkResource(db, request, &ran)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "users"."id" FROM "users" WHERE last_name ILIKE $1 LIMIT $2`)).
+		WithArgs("%bar%", DefaultBulkActionLimit+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+
+	var aryUsers []UserPrivate
+	is.Nil(res.DispatchBulkAction(res, aryUsers))
+	is.Equal([]any{int64(5)}, ran)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestDispatchBulkActionErrors(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	dispatch := func(body string) error {
+		request, _ := http.NewRequest(http.MethodPost, "/users/bulk", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var ran []any
+		var aryUsers []UserPrivate
+		res := newBulkResource(db, request, &ran)
+		return res.DispatchBulkAction(res, aryUsers)
+	}
+
+	is.ErrorIs(dispatch("action=explode&ids=1"), ErrValidation)
+	is.ErrorIs(dispatch("action=delete&ids[]=1&ids[]=2"), ErrForbidden)
+	is.ErrorIs(dispatch("action=archive"), ErrValidation)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "users"."id" FROM "users" WHERE "users"."id" IN ($1,$2) LIMIT $3`)).
+		WithArgs("3", "4", DefaultBulkActionLimit+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	is.ErrorIs(dispatch("action=archive&ids=3,4"), ErrValidation)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestDispatchBulkActionMethod(t *testing.T) {
+	is := assert.New(t)
+
+	var ran []any
+	var aryUsers []UserPrivate
+	request, _ := http.NewRequest(http.MethodGet, "/users/bulk?action=archive&all=1", nil)
+	res := newBulkResource(nil, request, &ran)
+
+	var verr *ValidationError
+	is.ErrorAs(res.DispatchBulkAction(res, aryUsers), &verr)
+	is.Equal("method", verr.Param)
+	is.Equal(http.MethodGet, verr.Key)
+
+	res = newBulkResource(nil, nil, &ran)
+	is.ErrorIs(res.DispatchBulkAction(res, aryUsers), ErrValidation)
+	is.Nil(ran)
+}
+
+func TestDispatchBulkActionLimit(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodPost, "/users/bulk", strings.NewReader("action=archive&all=1"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var ran []any
+	res := newBulkResource(db, request, &ran)
+	res.BulkActionLimit = 2
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "users"."id" FROM "users" LIMIT $1`)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+
+	var aryUsers []UserPrivate
+	is.ErrorIs(res.DispatchBulkAction(res, aryUsers), ErrBulkActionLimit)
+	is.Nil(ran)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestBulkActionsInTableProps(t *testing.T) {
+	is := assert.New(t)
+
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	var ran []any
+	res := newBulkResource(nil, request, &ran)
+
+	props := res.ToResponse(nil)["tableProps"].(TableProps)
+	is.Len(props.BulkActions, 1)
+	is.Equal("archive", props.BulkActions[0].Name)
+	is.Equal("Archive the selected users?", props.BulkActions[0].Confirm)
+
+	request.Header.Set("X-Role", "admin")
+	props = res.ToResponse(nil)["tableProps"].(TableProps)
+	is.Len(props.BulkActions, 2)
+}
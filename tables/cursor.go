@@ -0,0 +1,327 @@
+package tables
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Cursor marks the boundary row of a keyset page, it is sent to the client as an opaque string
+type Cursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+	Prev   bool   `json:"p,omitempty"`
+}
+
+// cursorTime tags time values in encoded cursors so they decode as time.Time instead of a
+// string, databases such as SQLite and MySQL would otherwise compare them as text
+type cursorTime struct {
+	Time time.Time `json:"t"`
+}
+
+// Encode returns the opaque string representation of the cursor
+func (c Cursor) Encode() string {
+	values := make([]any, len(c.Values))
+	for i, v := range c.Values {
+		if t, ok := v.(time.Time); ok {
+			v = cursorTime{Time: t}
+		}
+		values[i] = v
+	}
+	c.Values = values
+
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses an opaque cursor string
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cursor{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err = d.Decode(c); err != nil {
+		return nil, err
+	}
+
+	normalizeNumbers(c.Values)
+	for i, v := range c.Values {
+		if m, ok := v.(map[string]any); ok {
+			s, ok := m["t"].(string)
+			if len(m) != 1 || !ok {
+				return nil, fmt.Errorf("tables: invalid cursor value %v", v)
+			}
+			if c.Values[i], err = time.Parse(time.RFC3339Nano, s); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// normalizeNumbers converts values decoded as json.Number to int64 or float64
+// so they compare against integer columns
+func normalizeNumbers(values []any) {
+	for i, v := range values {
+		if n, ok := v.(json.Number); ok {
+			if iv, err := n.Int64(); err == nil {
+				values[i] = iv
+			} else if fv, err := n.Float64(); err == nil {
+				values[i] = fv
+			}
+		}
+	}
+}
+
+// sortColumn is a single ORDER BY column
+type sortColumn struct {
+	Column string
+	Desc   bool
+	// Attribute is the field attribute of a relation column read from preloaded rows
+	Attribute string
+	// NullsFirst reports whether NULLs sort before other values in the column's direction
+	NullsFirst bool
+	// NotNull marks columns that never hold NULL such as the primary key
+	NotNull bool
+}
+
+// parseSort splits an ORDER BY clause such as "last_name ASC, id DESC" into columns
+func parseSort(sort string) []sortColumn {
+	var cols []sortColumn
+	for _, part := range strings.Split(sort, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		cols = append(cols, sortColumn{
+			Column: fields[0],
+			Desc:   len(fields) > 1 && strings.EqualFold(fields[1], "DESC"),
+		})
+	}
+	return cols
+}
+
+// keysetColumns returns the sort columns with the primary key appended as a tie-breaker,
+// joined relation columns use their derived column. NULLs are placed by the field's NULL
+// ordering, or where the database sorts them
+func (r *AbstractResource) keysetColumns(q *gorm.DB, sort string) []sortColumn {
+	d := DialectOf(q)
+	pk := r.primaryKey()
+	cols := parseSort(sort)
+	hasPK := false
+	for i := range cols {
+		if f := r.field(cols[i].Column); f != nil && f.Nulls != NullsDefault {
+			cols[i].NullsFirst = f.Nulls == NullsFirst
+		} else {
+			cols[i].NullsFirst = d.NullsFirst(cols[i].Desc)
+		}
+		if column := r.columnOf(cols[i].Column); column != cols[i].Column {
+			cols[i].Attribute, cols[i].Column = cols[i].Column, column
+		}
+		if cols[i].Column == pk {
+			cols[i].NotNull = true
+			hasPK = true
+		}
+	}
+	if hasPK {
+		return cols
+	}
+
+	desc := len(cols) > 0 && cols[len(cols)-1].Desc
+	return append(cols, sortColumn{Column: pk, Desc: desc, NullsFirst: d.NullsFirst(desc), NotNull: true})
+}
+
+// primaryKey returns the configured primary key column
+func (r *AbstractResource) primaryKey() string {
+	if r.PrimaryKey == "" {
+		return "id"
+	}
+	return r.PrimaryKey
+}
+
+// keysetCondition builds the where clause selecting rows after (or before) the cursor values.
+// Columns are compared one by one, (a > ?) OR (a = ? AND b > ?) OR ..., so NULL values
+// match IS NULL branches placed where the ORDER BY sorts them
+func keysetCondition(cols []sortColumn, values []any, prev bool) (string, []any) {
+	var (
+		clauses []string
+		args    []any
+	)
+	for i, c := range cols {
+		after, afterArgs, ok := keysetAfter(c, values[i], prev)
+		if !ok {
+			continue
+		}
+
+		var parts []string
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				parts = append(parts, cols[j].Column+" IS NULL")
+				continue
+			}
+			parts = append(parts, cols[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		parts = append(parts, after)
+		args = append(args, afterArgs...)
+
+		if len(parts) == 1 {
+			clauses = append(clauses, parts[0])
+		} else {
+			clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+		}
+	}
+
+	switch len(clauses) {
+	case 0:
+		return "1 = 0", nil
+	case 1:
+		return clauses[0], args
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// keysetAfter returns the condition matching column values sorted after the value, ok is
+// false when nothing sorts after it such as a NULL placed last
+func keysetAfter(c sortColumn, value any, prev bool) (string, []any, bool) {
+	op, nullsFirst := ">", c.NullsFirst != prev
+	if c.Desc != prev {
+		op = "<"
+	}
+
+	if value == nil {
+		if nullsFirst {
+			return c.Column + " IS NOT NULL", nil, true
+		}
+		return "", nil, false
+	}
+	if c.NotNull || nullsFirst {
+		return c.Column + " " + op + " ?", []any{value}, true
+	}
+	return "(" + c.Column + " " + op + " ? OR " + c.Column + " IS NULL)", []any{value}, true
+}
+
+// keysetOrder orders the query by the keyset columns, reversed for previous pages. NULLs
+// are placed with the dialect when the column doesn't use the database's default placement
+func keysetOrder(q *gorm.DB, cols []sortColumn, reverse bool) {
+	d := DialectOf(q)
+	for _, c := range cols {
+		desc, nullsFirst := c.Desc != reverse, c.NullsFirst != reverse
+		if c.NotNull || nullsFirst == d.NullsFirst(desc) {
+			q.Order(orderTerm(c.Column, desc))
+		} else {
+			q.Order(d.OrderNulls(c.Column, desc, nullsFirst))
+		}
+	}
+}
+
+// cursorPaginate fetches a page of rows after or before the request cursor without counting rows
+func (r *AbstractResource) cursorPaginate(q *gorm.DB, model any, p *Pagination) (*Pagination, error) {
+	sort := p.GetSort()
+	cols := r.keysetColumns(q, sort)
+
+	var cursor *Cursor
+	if raw := r.requestURL().Query().Get("cursor"); raw != "" {
+		c, err := DecodeCursor(raw)
+		if err != nil || c.Sort != sort || len(c.Values) != len(cols) {
+			return nil, &ValidationError{Param: "cursor", Key: raw}
+		}
+		cursor = c
+
+		where, args := keysetCondition(cols, c.Values, c.Prev)
+		q.Where(where, args...)
+	}
+
+	prev := cursor != nil && cursor.Prev
+	keysetOrder(q, cols, prev)
+
+	r.eagerLoad(q)
+
+	// Fetch an extra row to find out if there is another page
+	if err := q.Limit(p.GetLimit() + 1).Find(&model).Error; err != nil {
+		return nil, newQueryError(q, "select", err)
+	}
+
+	rows, hasMore := trimRows(model, p.GetLimit())
+	p.HasMore = hasMore
+	if prev {
+		rows = reverseSlice(rows)
+	}
+	p.Rows = rows.Interface()
+
+	if rows.Len() == 0 {
+		return p, nil
+	}
+
+	boundary := func(i int, prev bool) (string, error) {
+		values, err := rowValues(q, rows.Index(i), cols)
+		if err != nil {
+			return "", err
+		}
+		return Cursor{Sort: sort, Values: values, Prev: prev}.Encode(), nil
+	}
+
+	var err error
+	if hasMore || prev {
+		if p.NextCursor, err = boundary(rows.Len()-1, false); err != nil {
+			return nil, err
+		}
+	}
+	if cursor != nil && (!prev || hasMore) {
+		if p.PrevCursor, err = boundary(0, true); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// rowValues reads the keyset column values from a result row
+func rowValues(q *gorm.DB, row reflect.Value, cols []sortColumn) ([]any, error) {
+	row = reflect.Indirect(row)
+	values := make([]any, len(cols))
+
+	for i, c := range cols {
+		name := c.Column
+		if c.Attribute != "" {
+			name = c.Attribute
+		}
+		if idx := strings.LastIndex(name, "."); idx >= 0 {
+			// Relation columns are read from the preloaded relation
+			if q.Statement.Schema != nil && name[:idx] != q.Statement.Schema.Table {
+				values[i] = plainValue(pathValue(row.Interface(), name))
+				continue
+			}
+			name = name[idx+1:]
+		}
+
+		if row.Kind() == reflect.Map {
+			v := row.MapIndex(reflect.ValueOf(name))
+			if !v.IsValid() {
+				return nil, fmt.Errorf("tables: cursor column %q not found in row", name)
+			}
+			values[i] = plainValue(v.Interface())
+			continue
+		}
+
+		if q.Statement.Schema == nil {
+			return nil, fmt.Errorf("tables: cursor column %q not found in row", name)
+		}
+		field := q.Statement.Schema.LookUpField(name)
+		if field == nil {
+			return nil, fmt.Errorf("tables: cursor column %q not found in row", name)
+		}
+		values[i], _ = field.ValueOf(q.Statement.Context, row)
+		values[i] = plainValue(values[i])
+	}
+	return values, nil
+}
@@ -0,0 +1,104 @@
+package tables
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCursorEncoding(t *testing.T) {
+	is := assert.New(t)
+
+	c := Cursor{Sort: "last_name ASC", Values: []any{"bar", uint(7), 1.5}, Prev: true}
+	decoded, err := DecodeCursor(c.Encode())
+
+	is.Nil(err)
+	is.Equal("last_name ASC", decoded.Sort)
+	is.Equal([]any{"bar", int64(7), 1.5}, decoded.Values)
+	is.True(decoded.Prev)
+
+	_, err = DecodeCursor("not a cursor")
+	is.NotNil(err)
+}
+
+func TestCursorEncodingTime(t *testing.T) {
+	is := assert.New(t)
+
+	at := time.Date(2024, 1, 1, 10, 0, 0, 500, time.FixedZone("CET", 3600))
+	decoded, err := DecodeCursor(Cursor{Sort: "created_at ASC", Values: []any{at, 3}}.Encode())
+
+	is.Nil(err)
+	is.IsType(time.Time{}, decoded.Values[0])
+	is.True(at.Equal(decoded.Values[0].(time.Time)))
+	is.Equal(int64(3), decoded.Values[1])
+
+	bad := Cursor{Sort: "created_at ASC", Values: []any{map[string]any{"x": 1}}}.Encode()
+	_, err = DecodeCursor(bad)
+	is.NotNil(err)
+}
+
+func TestParseSort(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal([]sortColumn{{Column: "id"}}, parseSort("id ASC"))
+	is.Equal([]sortColumn{{Column: "status"}, {Column: "created_at", Desc: true}}, parseSort("status ASC, created_at DESC"))
+	is.Nil(parseSort(""))
+}
+
+func TestKeysetCondition(t *testing.T) {
+	is := assert.New(t)
+
+	where, args := keysetCondition([]sortColumn{{Column: "id", NotNull: true}}, []any{1}, false)
+	is.Equal("id > ?", where)
+	is.Equal([]any{1}, args)
+
+	where, _ = keysetCondition([]sortColumn{{Column: "id", Desc: true, NotNull: true}}, []any{1}, false)
+	is.Equal("id < ?", where)
+
+	cols := []sortColumn{{Column: "last_name"}, {Column: "id", NotNull: true}}
+	where, args = keysetCondition(cols, []any{"bar", 1}, false)
+	is.Equal("((last_name > ? OR last_name IS NULL) OR (last_name = ? AND id > ?))", where)
+	is.Equal([]any{"bar", "bar", 1}, args)
+
+	where, args = keysetCondition(cols, []any{"bar", 1}, true)
+	is.Equal("(last_name < ? OR (last_name = ? AND id < ?))", where)
+	is.Equal([]any{"bar", "bar", 1}, args)
+
+	where, args = keysetCondition([]sortColumn{{Column: "status"}, {Column: "id", Desc: true, NotNull: true}}, []any{"open", 1}, false)
+	is.Equal("((status > ? OR status IS NULL) OR (status = ? AND id < ?))", where)
+	is.Equal([]any{"open", "open", 1}, args)
+}
+
+func TestKeysetConditionNulls(t *testing.T) {
+	is := assert.New(t)
+
+	// NULLs placed last, only NULL rows follow a NULL
+	cols := []sortColumn{{Column: "last_login"}, {Column: "id", NotNull: true}}
+	where, args := keysetCondition(cols, []any{nil, 5}, false)
+	is.Equal("(last_login IS NULL AND id > ?)", where)
+	is.Equal([]any{5}, args)
+
+	where, args = keysetCondition(cols, []any{nil, 5}, true)
+	is.Equal("(last_login IS NOT NULL OR (last_login IS NULL AND id < ?))", where)
+	is.Equal([]any{5}, args)
+
+	// NULLs placed first, every non NULL value follows a NULL
+	cols = []sortColumn{{Column: "last_login", NullsFirst: true}, {Column: "id", NotNull: true}}
+	where, _ = keysetCondition(cols, []any{nil, 5}, false)
+	is.Equal("(last_login IS NOT NULL OR (last_login IS NULL AND id > ?))", where)
+
+	where, _ = keysetCondition([]sortColumn{{Column: "last_login"}}, []any{nil}, false)
+	is.Equal("1 = 0", where)
+}
+
+func TestKeysetColumns(t *testing.T) {
+	is := assert.New(t)
+
+	r := &AbstractResource{}
+	// Standard SQL sorts NULLs first in ascending order
+	is.Equal([]sortColumn{{Column: "last_name", Desc: true}, {Column: "id", Desc: true, NotNull: true}}, r.keysetColumns(nil, "last_name DESC"))
+	is.Equal([]sortColumn{{Column: "id", NullsFirst: true, NotNull: true}}, r.keysetColumns(nil, "id ASC"))
+
+	r.PrimaryKey = "uuid"
+	is.Equal([]sortColumn{{Column: "id", NullsFirst: true}, {Column: "uuid", NullsFirst: true, NotNull: true}}, r.keysetColumns(nil, "id ASC"))
+}
@@ -0,0 +1,150 @@
+package tables
+
+import (
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Dialect adapts the SQL generated by tables to a database
+type Dialect interface {
+	// Contains returns a case insensitive substring condition on the column,
+	// the condition has a single placeholder for a "%value%" pattern
+	Contains(column string) string
+	// Quote quotes an identifier, dotted names are quoted per part
+	Quote(name string) string
+	// OrderNulls returns an ORDER BY term sorting NULLs first or last
+	OrderNulls(column string, desc, nullsFirst bool) string
+	// NullsFirst reports whether the database sorts NULLs first by default
+	NullsFirst(desc bool) bool
+	// FullTextSearch reports whether the database supports PostgreSQL's to_tsvector,
+	// websearch_to_tsquery and ts_rank functions
+	FullTextSearch() bool
+}
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = map[string]Dialect{
+		"postgres":  postgresDialect{},
+		"mysql":     mysqlDialect{},
+		"sqlite":    sqliteDialect{},
+		"sqlserver": sqlserverDialect{},
+	}
+)
+
+// RegisterDialect sets the dialect used for a gorm dialector name
+func RegisterDialect(name string, d Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[name] = d
+}
+
+// DialectOf returns the dialect for the database's gorm dialector, unknown
+// databases get a dialect using standard SQL
+func DialectOf(db *gorm.DB) Dialect {
+	if db == nil || db.Config == nil || db.Dialector == nil {
+		return ansiDialect{}
+	}
+
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	if d, ok := dialects[db.Dialector.Name()]; ok {
+		return d
+	}
+	return ansiDialect{}
+}
+
+// ansiDialect uses standard SQL and emulates NULLS FIRST/LAST
+type ansiDialect struct{}
+
+func (ansiDialect) Contains(column string) string {
+	return "LOWER(" + column + ") LIKE LOWER(?)"
+}
+
+func (ansiDialect) Quote(name string) string {
+	return quoteParts(name, `"`, `"`)
+}
+
+func (ansiDialect) OrderNulls(column string, desc, nullsFirst bool) string {
+	first, rest := "1", "0"
+	if nullsFirst {
+		first, rest = "0", "1"
+	}
+	return "CASE WHEN " + column + " IS NULL THEN " + first + " ELSE " + rest + " END, " + orderTerm(column, desc)
+}
+
+// NullsFirst treats NULLs as smaller than any value like MySQL, SQLite and SQL Server
+func (ansiDialect) NullsFirst(desc bool) bool {
+	return !desc
+}
+
+func (ansiDialect) FullTextSearch() bool {
+	return false
+}
+
+// postgresDialect uses ILIKE and native NULLS FIRST/LAST
+type postgresDialect struct{ ansiDialect }
+
+func (postgresDialect) Contains(column string) string {
+	return column + " ILIKE ?"
+}
+
+func (postgresDialect) OrderNulls(column string, desc, nullsFirst bool) string {
+	return nativeOrderNulls(column, desc, nullsFirst)
+}
+
+// NullsFirst treats NULLs as larger than any value
+func (postgresDialect) NullsFirst(desc bool) bool {
+	return desc
+}
+
+func (postgresDialect) FullTextSearch() bool {
+	return true
+}
+
+// mysqlDialect quotes with backticks
+type mysqlDialect struct{ ansiDialect }
+
+func (mysqlDialect) Quote(name string) string {
+	return quoteParts(name, "`", "`")
+}
+
+// sqliteDialect supports NULLS FIRST/LAST natively since SQLite 3.30
+type sqliteDialect struct{ ansiDialect }
+
+func (sqliteDialect) OrderNulls(column string, desc, nullsFirst bool) string {
+	return nativeOrderNulls(column, desc, nullsFirst)
+}
+
+// sqlserverDialect quotes with brackets
+type sqlserverDialect struct{ ansiDialect }
+
+func (sqlserverDialect) Quote(name string) string {
+	return quoteParts(name, "[", "]")
+}
+
+// orderTerm returns a column with its sort direction
+func orderTerm(column string, desc bool) string {
+	if desc {
+		return column + " DESC"
+	}
+	return column + " ASC"
+}
+
+// nativeOrderNulls uses the NULLS FIRST/LAST modifier
+func nativeOrderNulls(column string, desc, nullsFirst bool) string {
+	if nullsFirst {
+		return orderTerm(column, desc) + " NULLS FIRST"
+	}
+	return orderTerm(column, desc) + " NULLS LAST"
+}
+
+// quoteParts quotes every part of a dotted identifier, closing quotes are escaped by doubling them
+func quoteParts(name, open, close string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = open + strings.ReplaceAll(p, close, close+close) + close
+	}
+	return strings.Join(parts, ".")
+}
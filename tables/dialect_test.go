@@ -0,0 +1,206 @@
+package tables
+
+import (
+	"bytes"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestDialectOf(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, _ := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	is.Equal(postgresDialect{}, DialectOf(db))
+	is.Equal(ansiDialect{}, DialectOf(nil))
+
+	for name, want := range map[string]Dialect{
+		"mysql":     mysqlDialect{},
+		"sqlite":    sqliteDialect{},
+		"sqlserver": sqlserverDialect{},
+		"oracle":    ansiDialect{},
+	} {
+		namedDB, named, _ := testutils.NamedDBMock(t, name)
+		is.Equal(want, DialectOf(named), name)
+		namedDB.Close()
+	}
+
+	RegisterDialect("oracle", mysqlDialect{})
+	defer RegisterDialect("oracle", ansiDialect{})
+	oracleDB, oracle, _ := testutils.NamedDBMock(t, "oracle")
+	defer oracleDB.Close()
+	is.Equal(mysqlDialect{}, DialectOf(oracle))
+}
+
+func TestDialects(t *testing.T) {
+	is := assert.New(t)
+
+	tests := map[string]struct {
+		dialect  Dialect
+		contains string
+		quote    string
+		nulls    string
+	}{
+		"postgres":  {postgresDialect{}, `name ILIKE ?`, `"users"."name"`, `name DESC NULLS LAST`},
+		"sqlite":    {sqliteDialect{}, `LOWER(name) LIKE LOWER(?)`, `"users"."name"`, `name DESC NULLS LAST`},
+		"mysql":     {mysqlDialect{}, `LOWER(name) LIKE LOWER(?)`, "`users`.`name`", `CASE WHEN name IS NULL THEN 1 ELSE 0 END, name DESC`},
+		"sqlserver": {sqlserverDialect{}, `LOWER(name) LIKE LOWER(?)`, `[users].[name]`, `CASE WHEN name IS NULL THEN 1 ELSE 0 END, name DESC`},
+	}
+
+	for name, tt := range tests {
+		is.Equal(tt.contains, tt.dialect.Contains("name"), name)
+		is.Equal(tt.quote, tt.dialect.Quote("users.name"), name)
+		is.Equal(tt.nulls, tt.dialect.OrderNulls("name", true, false), name)
+	}
+
+	is.Equal(`"a""b"`, ansiDialect{}.Quote(`a"b`))
+	is.Equal(`[a]]b]`, sqlserverDialect{}.Quote(`a]b`))
+	is.Equal(`name ASC NULLS FIRST`, postgresDialect{}.OrderNulls("name", false, true))
+	is.Equal(`CASE WHEN name IS NULL THEN 0 ELSE 1 END, name ASC`, ansiDialect{}.OrderNulls("name", false, true))
+	is.False(postgresDialect{}.NullsFirst(false))
+	is.True(mysqlDialect{}.NullsFirst(false))
+	is.False(sqlserverDialect{}.NullsFirst(true))
+	is.True(postgresDialect{}.FullTextSearch())
+	is.False(sqliteDialect{}.FullTextSearch())
+}
+
+func TestDialectQueries(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.NamedDBMock(t, "mysql")
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, "/users?search[last_name]=Foo&sort=-last_name", nil)
+	res := NewUserResource(db, request)
+	res.Fields[2] = NewField("Last name", WithSortable(), WithSearchable(), WithNullsLast())
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE LOWER(last_name) LIKE LOWER($1)`)).
+		WithArgs("%Foo%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY CASE WHEN last_name IS NULL THEN 1 ELSE 0 END, last_name DESC LIMIT $2`)).
+		WithArgs("%Foo%", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestDialectQuotedJoins(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.NamedDBMock(t, "mysql")
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=client.title&perPage=1", nil)
+	res := NewUserResource(db, request)
+	res.Fields = append(res.Fields, NewField("Client", WithAttribute("client.title"), WithSortable()))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM (SELECT `users`.*, `client`.`title` AS `client__title` FROM \"users\" " +
+		"LEFT JOIN `clients` `client` ON `client`.`id` = `users`.`client_id`) AS `users`")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY `client__title` ASC LIMIT $1")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestDialectExportNulls(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.NamedDBMock(t, "mysql")
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=last_name&hidden=first_name,email,username,last_login", nil)
+	res := NewUserResource(db, request)
+	res.ExportBatchSize = 1
+	res.Fields[2] = NewField("Last name", WithSortable(), WithNullsLast())
+
+	order := ` ORDER BY CASE WHEN last_name IS NULL THEN 1 ELSE 0 END, last_name ASC,id ASC LIMIT `
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"` + order + `$1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}).AddRow(1, "a"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE ((last_name > $1 OR last_name IS NULL) OR (last_name = $2 AND id > $3))`+order+`$4`)).
+		WithArgs("a", "a", 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}))
+
+	var buf bytes.Buffer
+	var aryUsers []UserPrivate
+	is.Nil(res.Export(request.Context(), res, aryUsers, NewCSVWriter(&buf)))
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestDialectSQLServerCursorNullOrder(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.NamedDBMock(t, "sqlserver")
+	defer sqlDB.Close()
+
+	cursor := Cursor{Sort: "last_name ASC", Values: []any{"b", 2}}.Encode()
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=last_name&cursor="+cursor, nil)
+	res := NewUserResource(db, request)
+	res.PaginationMode = CursorPagination
+
+	// Attributes are used as written, without brackets. SQL Server sorts NULLs first, so after
+	// a non NULL value the keyset has no NULL checks and the order no CASE terms
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE (last_name > $1 OR (last_name = $2 AND id > $3)) ORDER BY last_name ASC,id ASC`)).
+		WithArgs("b", "b", 2, 26).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestDialectFullTextUnsupported(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.NamedDBMock(t, "sqlite")
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, "/users?search[global]=john", nil)
+	res := NewUserResource(db, request)
+	res.FullTextSearch = &FullTextSearch{}
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.ErrorIs(err, ErrUnsupported)
+	is.Contains(err.Error(), "sqlite")
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+// cockroachDialect is a PostgreSQL compatible dialect registered under another name
+type cockroachDialect struct{ postgresDialect }
+
+func TestDialectFullTextRegistered(t *testing.T) {
+	is := assert.New(t)
+	RegisterDialect("cockroachdb", cockroachDialect{})
+	sqlDB, db, mock := testutils.NamedDBMock(t, "cockroachdb")
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, "/users?search[global]=john", nil)
+	res := NewUserResource(db, request)
+	res.FullTextSearch = &FullTextSearch{Vector: "search_vector"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE search_vector @@ websearch_to_tsquery('simple', $1)`)).
+		WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE search_vector @@ websearch_to_tsquery('simple', $1) ORDER BY id ASC LIMIT $2`)).
+		WithArgs("john", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
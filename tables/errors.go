@@ -0,0 +1,78 @@
+package tables
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrValidation matches every ValidationError
+	ErrValidation = errors.New("tables: invalid request")
+	// ErrQuery matches every QueryError, including count failures
+	ErrQuery = errors.New("tables: query failed")
+	// ErrCount matches QueryErrors raised while counting records
+	ErrCount = errors.New("tables: count failed")
+	// ErrForbidden is returned when the request is not authorized to run an action
+	ErrForbidden = errors.New("tables: action not allowed")
+	// ErrArraySortLimit is returned when more rows match than can be sorted in memory
+	ErrArraySortLimit = errors.New("tables: too many rows to sort in memory")
+	// ErrBulkActionLimit is returned when a bulk action selects more records than allowed
+	ErrBulkActionLimit = errors.New("tables: too many records for bulk action")
+	// ErrXLSXRowLimit is returned when an Excel export has more rows than a sheet can hold
+	ErrXLSXRowLimit = errors.New("tables: too many rows for an Excel sheet")
+	// ErrUnsupported is returned when a feature is not available for the database's dialect
+	ErrUnsupported = errors.New("tables: not supported by the database")
+	// ErrConfiguration is returned when the resource's fields don't match its model
+	ErrConfiguration = errors.New("tables: invalid resource configuration")
+)
+
+// ValidationError is returned when a request references a sort, search or
+// filter key that has not been whitelisted on the resource, or supplies a
+// value the key cannot accept
+type ValidationError struct {
+	Param  string
+	Key    string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("tables: invalid %s %q: %s", e.Param, e.Key, e.Reason)
+	}
+	return fmt.Sprintf("tables: invalid %s key %q", e.Param, e.Key)
+}
+
+// Is allows errors.Is(err, ErrValidation)
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// QueryError wraps a database error raised while counting or fetching records
+type QueryError struct {
+	Op  string
+	Err error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("tables: %s query failed: %v", e.Op, e.Err)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Is allows errors.Is(err, ErrQuery) and errors.Is(err, ErrCount) for count failures
+func (e *QueryError) Is(target error) bool {
+	return target == ErrQuery || (target == ErrCount && e.Op == "count")
+}
+
+// newQueryError wraps a failed query, keeping the context error in the chain
+// when the driver reports cancellation with its own error
+func newQueryError(q *gorm.DB, op string, err error) *QueryError {
+	if ctx := q.Statement.Context; ctx != nil && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+		err = fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+	return &QueryError{Op: op, Err: err}
+}
@@ -0,0 +1,28 @@
+package tables
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestErrorMatching(t *testing.T) {
+	is := assert.New(t)
+	dbErr := errors.New("connection refused")
+
+	var err error = &ValidationError{Param: "sort", Key: "password"}
+	is.ErrorIs(err, ErrValidation)
+	is.NotErrorIs(err, ErrQuery)
+	is.Equal(`tables: invalid sort key "password"`, err.Error())
+
+	err = &QueryError{Op: "count", Err: dbErr}
+	is.ErrorIs(err, ErrQuery)
+	is.ErrorIs(err, ErrCount)
+	is.ErrorIs(err, dbErr)
+	is.NotErrorIs(err, ErrValidation)
+
+	err = &QueryError{Op: "select", Err: dbErr}
+	is.ErrorIs(err, ErrQuery)
+	is.NotErrorIs(err, ErrCount)
+	is.Equal("tables: select query failed: connection refused", err.Error())
+}
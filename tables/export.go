@@ -0,0 +1,160 @@
+package tables
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultExportBatchSize is the number of rows fetched per query when exporting
+const DefaultExportBatchSize = 1000
+
+// ExportWriter encodes exported records, the header is written once before any rows
+type ExportWriter interface {
+	WriteHeader(fields []*Field) error
+	WriteRow(values []any) error
+	Close() error
+}
+
+// Export streams every record matching the request's search, filters and sort
+// through the writer, using only the visible fields. Rows are fetched in batches
+// of ExportBatchSize using keyset conditions so memory use stays constant.
+//
+// The header is written after the first batch is fetched, so a failing query
+// returns an error before anything is written. QueryTimeout is not applied to exports
+func (r *AbstractResource) Export(ctx context.Context, resource ITable, model any, w ExportWriter) error {
+	q, p, err := r.prepareQuery(ctx, resource, model)
+	if err != nil {
+		return err
+	}
+
+	fields := r.exportFields()
+	written := false
+
+	write := func(rows reflect.Value) error {
+		if !written {
+			if err := w.WriteHeader(fields); err != nil {
+				return err
+			}
+			written = true
+		}
+		for i := 0; i < rows.Len(); i++ {
+			values := make([]any, len(fields))
+			for j, f := range fields {
+				v := pathValue(rows.Index(i).Interface(), f.Attribute)
+				if f.masked(r.Request) {
+					v = f.Mask(v)
+				}
+				values[j] = plainValue(v)
+			}
+			if err := w.WriteRow(values); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Fields sorted in memory are exported in a single capped batch
+	if cols := r.arraySortColumns(p.GetSort()); cols != nil {
+		r.eagerLoad(q)
+		p, err = r.arrayPaginate(q, model, &Pagination{Limit: r.arraySortLimit(), Page: 1}, cols)
+		if err != nil {
+			return err
+		}
+		if err = write(reflect.ValueOf(p.Rows)); err != nil {
+			return err
+		}
+		return w.Close()
+	}
+
+	var (
+		cols  = r.keysetColumns(q, p.GetSort())
+		base  = q.Session(&gorm.Session{})
+		size  = r.exportBatchSize()
+		after []any
+	)
+
+	for {
+		tx := base.Limit(size)
+		if after != nil {
+			where, args := keysetCondition(cols, after, false)
+			tx.Where(where, args...)
+		}
+		keysetOrder(tx, cols, false)
+		r.eagerLoad(tx)
+
+		batch := reflect.New(reflect.TypeOf(model))
+		if err := tx.Find(batch.Interface()).Error; err != nil {
+			return newQueryError(tx, "select", err)
+		}
+
+		rows := batch.Elem()
+		if err := write(rows); err != nil {
+			return err
+		}
+		if rows.Len() < size {
+			break
+		}
+
+		if after, err = rowValues(tx, rows.Index(rows.Len()-1), cols); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+// exportFields returns the visible fields holding record values the request may see
+func (r *AbstractResource) exportFields() []*Field {
+	r.FlagVisibility()
+
+	var fields []*Field
+	for _, f := range r.Fields {
+		if f.Visible && f.Actions == nil && !f.denied(r.Request) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// exportBatchSize returns the configured export batch size
+func (r *AbstractResource) exportBatchSize() int {
+	if r.ExportBatchSize == 0 {
+		return DefaultExportBatchSize
+	}
+	return r.ExportBatchSize
+}
+
+// serveExport sets the download headers and streams the export to the response
+func (r *AbstractResource) serveExport(w http.ResponseWriter, contentType, filename string, resource ITable, model any, ew ExportWriter) error {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	ctx := context.Background()
+	if r.Request != nil {
+		ctx = r.Request.Context()
+	}
+	return r.Export(ctx, resource, model, ew)
+}
+
+// exportString formats a record value as text, NULL values are empty
+func exportString(v any) string {
+	switch val := plainValue(v).(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.Format(time.RFC3339)
+	case []byte:
+		return string(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
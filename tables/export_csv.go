@@ -0,0 +1,57 @@
+package tables
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CSVWriter encodes exported records as CSV using field names as the header
+type CSVWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter creates a CSV export writer
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (c *CSVWriter) WriteHeader(fields []*Field) error {
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.Name
+	}
+	return c.w.Write(header)
+}
+
+func (c *CSVWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = escapeFormula(exportString(v))
+	}
+	return c.w.Write(record)
+}
+
+func (c *CSVWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula prefixes cells spreadsheets would evaluate as formulas with a quote,
+// numbers such as "-5" are kept as they are
+func escapeFormula(s string) string {
+	if s == "" || !strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return s
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	return "'" + s
+}
+
+// ServeCSV streams the records matching the request as a CSV download
+func (r *AbstractResource) ServeCSV(w http.ResponseWriter, resource ITable, model any, filename string) error {
+	return r.serveExport(w, "text/csv; charset=utf-8", filename, resource, model, NewCSVWriter(w))
+}
@@ -0,0 +1,61 @@
+package tables
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// NDJSONWriter encodes exported records as newline delimited JSON objects keyed
+// by field attribute, in field order. Driver values such as sql.NullString are
+// written as their value or null
+type NDJSONWriter struct {
+	w          *bufio.Writer
+	attributes [][]byte
+}
+
+// NewNDJSONWriter creates a JSON Lines export writer
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{w: bufio.NewWriter(w)}
+}
+
+// WriteHeader records the attributes used as object keys, no header line is written
+func (n *NDJSONWriter) WriteHeader(fields []*Field) error {
+	n.attributes = make([][]byte, len(fields))
+	for i, f := range fields {
+		key, err := json.Marshal(f.Attribute)
+		if err != nil {
+			return err
+		}
+		n.attributes[i] = key
+	}
+	return nil
+}
+
+func (n *NDJSONWriter) WriteRow(values []any) error {
+	n.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			n.w.WriteByte(',')
+		}
+		val, err := json.Marshal(plainValue(v))
+		if err != nil {
+			return err
+		}
+		n.w.Write(n.attributes[i])
+		n.w.WriteByte(':')
+		n.w.Write(val)
+	}
+	_, err := n.w.WriteString("}\n")
+	return err
+}
+
+func (n *NDJSONWriter) Close() error {
+	return n.w.Flush()
+}
+
+// ServeNDJSON streams the records matching the request as newline delimited JSON
+func (r *AbstractResource) ServeNDJSON(w http.ResponseWriter, resource ITable, model any, filename string) error {
+	return r.serveExport(w, "application/x-ndjson", filename, resource, model, NewNDJSONWriter(w))
+}
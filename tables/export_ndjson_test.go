@@ -0,0 +1,80 @@
+package tables
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestExportNDJSON(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?hidden=email,username,last_login&filters[id][gte]=2", nil)
+	res := NewUserResource(db, request)
+	res.ExportBatchSize = 2
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id >= $1 ORDER BY id ASC LIMIT $2`)).
+		WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name"}).
+			AddRow(2, "foo", "bar").
+			AddRow(3, "Say \"hi\"", ""))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id >= $1 AND id > $2 ORDER BY id ASC LIMIT $3`)).
+		WithArgs(2, 3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name"}))
+
+	w := httptest.NewRecorder()
+	var aryUsers []UserPrivate
+	is.Nil(res.ServeNDJSON(w, res, aryUsers, "users.ndjson"))
+
+	is.Equal("application/x-ndjson", w.Header().Get("Content-Type"))
+	is.Equal(`{"id":2,"first_name":"foo","last_name":"bar"}
+{"id":3,"first_name":"Say \"hi\"","last_name":""}
+`, w.Body.String())
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	n := NewNDJSONWriter(&buf)
+	is.Nil(n.WriteHeader([]*Field{NewField("Created at"), NewField("Total")}))
+	is.Nil(n.WriteRow([]any{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), nil}))
+	is.Nil(n.Close())
+
+	is.Equal(`{"created_at":"2024-01-02T00:00:00Z","total":null}`+"\n", buf.String())
+}
+
+func TestExportNDJSONNullableValues(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newNullableExport(t)
+	defer closeDB()
+
+	var buf bytes.Buffer
+	var users []nullableUser
+	is.Nil(res.Export(context.Background(), res, users, NewNDJSONWriter(&buf)))
+	is.Equal(`{"id":1,"nickname":"neo","score":5,"last_login":"2024-01-02T03:04:05Z","deleted_at":null}`+"\n"+
+		`{"id":2,"nickname":null,"score":null,"last_login":null,"deleted_at":null}`+"\n", buf.String())
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestNDJSONWriterValuers(t *testing.T) {
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	w := NewNDJSONWriter(&buf)
+	is.Nil(w.WriteHeader([]*Field{NewField("Name"), NewField("Seen")}))
+	is.Nil(w.WriteRow([]any{sql.NullString{String: "x", Valid: true}, sql.NullTime{}}))
+	is.Nil(w.Close())
+	is.Equal(`{"name":"x","seen":null}`+"\n", buf.String())
+}
@@ -0,0 +1,207 @@
+package tables
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestExportCSV(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?hidden=email,username&search[last_name]=ba", nil)
+	res := NewUserResource(db, request)
+	res.ExportBatchSize = 2
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE last_name ILIKE $1 ORDER BY id ASC LIMIT $2`)).
+		WithArgs("%ba%", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name"}).
+			AddRow(1, "foo", "bar").
+			AddRow(2, "Jo, Jr", "baz"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE last_name ILIKE $1 AND id > $2 ORDER BY id ASC LIMIT $3`)).
+		WithArgs("%ba%", 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name"}).
+			AddRow(3, "qux", "bat"))
+
+	w := httptest.NewRecorder()
+	var aryUsers []UserPrivate
+	err := res.ServeCSV(w, res, aryUsers, "users.csv")
+
+	is.Nil(err)
+	is.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	is.Equal(`attachment; filename=users.csv`, w.Header().Get("Content-Disposition"))
+	is.Equal("ID,First name,Last name,Last login\n1,foo,bar,\n2,\"Jo, Jr\",baz,\n3,qux,bat,\n", w.Body.String())
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestExportSortedDesc(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=-last_name&hidden=first_name,email,username,last_login", nil)
+	res := NewUserResource(db, request)
+	res.ExportBatchSize = 1
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY last_name DESC,id DESC LIMIT $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}).AddRow(4, "zed"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE (last_name < $1 OR (last_name = $2 AND id < $3)) ORDER BY last_name DESC,id DESC LIMIT $4`)).
+		WithArgs("zed", "zed", 4, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}))
+
+	var buf bytes.Buffer
+	var aryUsers []UserPrivate
+	err := res.Export(request.Context(), res, aryUsers, NewCSVWriter(&buf))
+
+	is.Nil(err)
+	is.Equal("ID,Last name\n4,zed\n", buf.String())
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+type loginUser struct {
+	ID        uint       `json:"id"`
+	LastLogin *time.Time `json:"last_login"`
+}
+
+func (loginUser) TableName() string {
+	return "users"
+}
+
+func TestExportNullBoundary(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=last_login&hidden=first_name,last_name,email,username", nil)
+	res := NewUserResource(db, request)
+	res.ExportBatchSize = 2
+	login := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY last_login ASC,id ASC LIMIT $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_login"}).
+			AddRow(1, login).
+			AddRow(2, nil))
+
+	// The batch ends on a NULL, NULLs sort last so the rest are NULL rows with a greater id
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE (last_login IS NULL AND id > $1) ORDER BY last_login ASC,id ASC LIMIT $2`)).
+		WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_login"}).
+			AddRow(3, nil))
+
+	var buf bytes.Buffer
+	var users []loginUser
+	err := res.Export(request.Context(), res, users, NewCSVWriter(&buf))
+
+	is.Nil(err)
+	is.Equal("ID,Last login\n1,2024-01-02T00:00:00Z\n2,\n3,\n", buf.String())
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestCSVFormulaEscaping(t *testing.T) {
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	is.Nil(w.WriteRow([]any{"=SUM(A1)", "+1 555", "-cmd", "@x", -5, "-2.5", "a=b", ""}))
+	is.Nil(w.Close())
+	is.Equal("'=SUM(A1),'+1 555,'-cmd,'@x,-5,-2.5,a=b,\n", buf.String())
+}
+
+type nullableUser struct {
+	ID        uint           `json:"id"`
+	Nickname  sql.NullString `json:"nickname"`
+	Score     sql.NullInt64  `json:"score"`
+	LastLogin sql.NullTime   `json:"last_login"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+}
+
+func (nullableUser) TableName() string {
+	return "users"
+}
+
+// newNullableExport expects a page of nullable values, one row set and one NULL
+func newNullableExport(t *testing.T) (*UserResource, sqlmock.Sqlmock, func() error) {
+	sqlDB, db, mock := testutils.DBMock(t)
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+	res.Fields = []*Field{
+		NewField("ID"),
+		NewField("Nickname"),
+		NewField("Score"),
+		NewField("Last login"),
+		NewField("Deleted at"),
+	}
+
+	login := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."deleted_at" IS NULL ORDER BY id ASC LIMIT $1`)).
+		WithArgs(DefaultExportBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nickname", "score", "last_login", "deleted_at"}).
+			AddRow(1, "neo", 5, login, nil).
+			AddRow(2, nil, nil, nil, nil))
+	return res, mock, sqlDB.Close
+}
+
+func TestExportNullableValues(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newNullableExport(t)
+	defer closeDB()
+
+	var buf bytes.Buffer
+	var users []nullableUser
+	err := res.Export(context.Background(), res, users, NewCSVWriter(&buf))
+
+	is.Nil(err)
+	is.Equal("ID,Nickname,Score,Last login,Deleted at\n1,neo,5,2024-01-02T03:04:05Z,\n2,,,,\n", buf.String())
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestExportErrors(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=password", nil)
+	res := NewUserResource(db, request)
+
+	var buf bytes.Buffer
+	var aryUsers []UserPrivate
+	is.ErrorIs(res.Export(request.Context(), res, aryUsers, NewCSVWriter(&buf)), ErrValidation)
+
+	request, _ = http.NewRequest(http.MethodGet, "/users", nil)
+	res = NewUserResource(db, request)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WillReturnError(errors.New("connection refused"))
+
+	is.ErrorIs(res.Export(request.Context(), res, aryUsers, NewCSVWriter(&buf)), ErrQuery)
+	is.Empty(buf.String())
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestExportString(t *testing.T) {
+	is := assert.New(t)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	name := "foo"
+	var missing *string
+
+	is.Equal("", exportString(nil))
+	is.Equal("", exportString(missing))
+	is.Equal("foo", exportString(&name))
+	is.Equal("2024-01-02T03:04:05Z", exportString(ts))
+	is.Equal("", exportString(time.Time{}))
+	is.Equal("1.5", exportString(1.5))
+	is.Equal("raw", exportString([]byte("raw")))
+}
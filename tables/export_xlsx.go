@@ -0,0 +1,192 @@
+package tables
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+	// Cell styles: 0 default, 1 bold header, 2 date time
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs></styleSheet>`
+
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxMaxRows is the number of rows Excel can open in a sheet, header included
+const xlsxMaxRows = 1048576
+
+// excelEpoch is the base of spreadsheet date serial numbers
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// XLSXWriter encodes exported records as a single sheet Excel workbook. The sheet
+// is streamed as rows are written, numbers and booleans keep their cell types and
+// dates are written as date cells in their own time zone. Rows past Excel's sheet
+// limit return ErrXLSXRowLimit
+type XLSXWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	row   int
+}
+
+// NewXLSXWriter creates an Excel export writer
+func NewXLSXWriter(w io.Writer) *XLSXWriter {
+	return &XLSXWriter{zip: zip.NewWriter(w)}
+}
+
+// WriteHeader writes the workbook parts and a frozen bold header row
+func (x *XLSXWriter) WriteHeader(fields []*Field) error {
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		w, err := x.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(w, part.body); err != nil {
+			return err
+		}
+	}
+
+	w, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(w)
+	x.sheet.WriteString(xlsxSheetStart)
+
+	names := make([]any, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return x.writeRow(names, 1)
+}
+
+func (x *XLSXWriter) WriteRow(values []any) error {
+	return x.writeRow(values, 0)
+}
+
+func (x *XLSXWriter) writeRow(values []any, style int) error {
+	if x.row >= xlsxMaxRows {
+		return ErrXLSXRowLimit
+	}
+	x.row++
+	row := strconv.Itoa(x.row)
+
+	x.sheet.WriteString(`<row r="` + row + `">`)
+	for i, v := range values {
+		ref := xlsxColumn(i) + row
+		x.writeCell(ref, v, style)
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+// writeCell writes a typed cell, driver values such as sql.NullInt64 are unwrapped
+// and empty or NULL values are skipped
+func (x *XLSXWriter) writeCell(ref string, v any, style int) {
+	rv := reflect.ValueOf(plainValue(v))
+	if !rv.IsValid() {
+		return
+	}
+
+	attrs := `<c r="` + ref + `"`
+	if style != 0 {
+		attrs += ` s="` + strconv.Itoa(style) + `"`
+	}
+
+	if t, ok := rv.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return
+		}
+		if style == 0 {
+			attrs += ` s="2"`
+		}
+		x.sheet.WriteString(attrs + `><v>` + strconv.FormatFloat(excelSerial(t), 'f', -1, 64) + `</v></c>`)
+		return
+	}
+
+	switch {
+	case rv.CanInt():
+		x.sheet.WriteString(attrs + `><v>` + strconv.FormatInt(rv.Int(), 10) + `</v></c>`)
+		return
+	case rv.CanUint():
+		x.sheet.WriteString(attrs + `><v>` + strconv.FormatUint(rv.Uint(), 10) + `</v></c>`)
+		return
+	case rv.CanFloat() && !math.IsNaN(rv.Float()) && !math.IsInf(rv.Float(), 0):
+		x.sheet.WriteString(attrs + `><v>` + strconv.FormatFloat(rv.Float(), 'f', -1, 64) + `</v></c>`)
+		return
+	case rv.Kind() == reflect.Bool:
+		b := "0"
+		if rv.Bool() {
+			b = "1"
+		}
+		x.sheet.WriteString(attrs + ` t="b"><v>` + b + `</v></c>`)
+		return
+	}
+
+	x.sheet.WriteString(attrs + ` t="inlineStr"><is><t xml:space="preserve">`)
+	_ = xml.EscapeText(x.sheet, []byte(exportString(rv.Interface())))
+	x.sheet.WriteString(`</t></is></c>`)
+}
+
+// Close finishes the sheet and workbook, an empty export still writes the header parts
+func (x *XLSXWriter) Close() error {
+	if x.sheet == nil {
+		if err := x.WriteHeader(nil); err != nil {
+			return err
+		}
+	}
+	x.sheet.WriteString(xlsxSheetEnd)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// xlsxColumn converts a zero based column index to its letter reference (A, B, ... AA)
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// excelSerial converts a time to a spreadsheet date serial using its wall clock
+func excelSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return float64(wall.Unix()-excelEpoch.Unix())/86400 + float64(wall.Nanosecond())/86400e9
+}
+
+// ServeXLSX streams the records matching the request as an Excel download
+func (r *AbstractResource) ServeXLSX(w http.ResponseWriter, resource ITable, model any, filename string) error {
+	return r.serveExport(w, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", filename, resource, model, NewXLSXWriter(w))
+}
@@ -0,0 +1,121 @@
+package tables
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestExportXLSX(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?hidden=last_name,email,username,last_login", nil)
+	res := NewUserResource(db, request)
+	res.Fields = append(res.Fields, NewField("Created at"))
+
+	created := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(DefaultExportBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "created_at"}).
+			AddRow(7, "Tom & <Jerry>", created))
+
+	w := httptest.NewRecorder()
+	var aryUsers []UserPrivate
+	is.Nil(res.ServeXLSX(w, res, aryUsers, "users.xlsx"))
+	is.Equal("application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", w.Header().Get("Content-Type"))
+
+	files := readZip(t, w.Body.Bytes())
+	is.Contains(files, "[Content_Types].xml")
+	is.Contains(files, "xl/workbook.xml")
+	is.Contains(files, "xl/styles.xml")
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	is.Contains(sheet, `<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	is.Contains(sheet, `<row r="1"><c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">ID</t></is></c><c r="B1" s="1" t="inlineStr"><is><t xml:space="preserve">First name</t></is></c><c r="C1" s="1" t="inlineStr"><is><t xml:space="preserve">Created at</t></is></c></row>`)
+	is.Contains(sheet, `<c r="A2"><v>7</v></c>`)
+	is.Contains(sheet, `<t xml:space="preserve">Tom &amp; &lt;Jerry&gt;</t>`)
+	is.Contains(sheet, `<c r="C2" s="2"><v>45293.5</v></c>`)
+	is.NotContains(sheet, "Last name")
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestXLSXCells(t *testing.T) {
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	x := NewXLSXWriter(&buf)
+	is.Nil(x.WriteHeader([]*Field{NewField("A")}))
+
+	var missing *int
+	is.Nil(x.WriteRow([]any{1.25, true, uint(3), missing, nil, "text"}))
+	is.Nil(x.Close())
+
+	sheet := readZip(t, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	is.Contains(sheet, `<row r="2"><c r="A2"><v>1.25</v></c><c r="B2" t="b"><v>1</v></c><c r="C2"><v>3</v></c><c r="F2" t="inlineStr">`)
+	is.Contains(sheet, `</sheetData></worksheet>`)
+}
+
+func TestXLSXNullableCells(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newNullableExport(t)
+	defer closeDB()
+
+	var buf bytes.Buffer
+	var users []nullableUser
+	is.Nil(res.Export(context.Background(), res, users, NewXLSXWriter(&buf)))
+	is.Nil(mock.ExpectationsWereMet())
+
+	// Valid values keep their cell types, NULLs leave the cells out
+	sheet := readZip(t, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	is.Contains(sheet, `<row r="2"><c r="A2"><v>1</v></c><c r="B2" t="inlineStr"><is><t xml:space="preserve">neo</t></is></c><c r="C2"><v>5</v></c><c r="D2" s="2"><v>`)
+	is.Contains(sheet, `<row r="3"><c r="A3"><v>2</v></c></row>`)
+}
+
+func TestXLSXRowLimit(t *testing.T) {
+	is := assert.New(t)
+
+	var buf bytes.Buffer
+	x := NewXLSXWriter(&buf)
+	is.Nil(x.WriteHeader([]*Field{NewField("A")}))
+
+	x.row = xlsxMaxRows - 1
+	is.Nil(x.WriteRow([]any{1}))
+	is.ErrorIs(x.WriteRow([]any{2}), ErrXLSXRowLimit)
+}
+
+func TestXLSXColumn(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal("A", xlsxColumn(0))
+	is.Equal("Z", xlsxColumn(25))
+	is.Equal("AA", xlsxColumn(26))
+	is.Equal("AZ", xlsxColumn(51))
+	is.Equal("BA", xlsxColumn(52))
+}
+
+// readZip returns the contents of each file in a zip archive
+func readZip(t *testing.T, data []byte) map[string]string {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	return files
+}
@@ -1,7 +1,10 @@
 package tables
 
 import (
+	"net/http"
+
 	"github.com/humweb/go-tables/utils"
+	"gorm.io/gorm"
 )
 
 // A Field represents a table field
@@ -16,8 +19,33 @@ type Field struct {
 	HasArraySort bool                   `json:"has_array_sort"`
 	Actions      []*ActionItems         `json:"actions,omitempty"`
 	Meta         map[string]interface{} `json:"meta,omitempty"`
+
+	// ArraySortFunc compares two records when sorting in memory
+	ArraySortFunc func(a, b any) int `json:"-"`
+	// Authorize reports whether the request may see the field
+	Authorize func(r *http.Request) bool `json:"-"`
+	// Mask replaces the field's values for requests Authorize rejects
+	Mask func(value any) any `json:"-"`
+	// CountRelation is the relation counted by count fields
+	CountRelation string `json:"-"`
+	// CountScope limits the related rows counted by count fields
+	CountScope func(*gorm.DB) *gorm.DB `json:"-"`
+	// Nulls places NULL values first or last when sorting on the field
+	Nulls NullsOrder `json:"-"`
 }
 
+// NullsOrder selects where NULL values are placed when sorting
+type NullsOrder int
+
+const (
+	// NullsDefault leaves NULL placement to the database
+	NullsDefault NullsOrder = iota
+	// NullsFirst sorts NULL values before other values
+	NullsFirst
+	// NullsLast sorts NULL values after other values
+	NullsLast
+)
+
 type FieldOption func(*Field)
 
 // NewField creates a new table field
@@ -52,6 +80,21 @@ func NewActionField(name string, actions []*ActionItems) *Field {
 	return s
 }
 
+// allowed reports whether the request may see, sort and search the field
+func (f *Field) allowed(req *http.Request) bool {
+	return f.Authorize == nil || (req != nil && f.Authorize(req))
+}
+
+// denied reports whether the field is dropped for the request
+func (f *Field) denied(req *http.Request) bool {
+	return f.Mask == nil && !f.allowed(req)
+}
+
+// masked reports whether the field's values are masked for the request
+func (f *Field) masked(req *http.Request) bool {
+	return f.Mask != nil && !f.allowed(req)
+}
+
 //
 // Filter Options
 //
@@ -109,3 +152,42 @@ func WithArraySort() FieldOption {
 		s.HasArraySort = true
 	}
 }
+
+// WithArraySortFunc sorts the results by slice using a custom record comparator
+func WithArraySortFunc(fn func(a, b any) int) FieldOption {
+	return func(s *Field) {
+		s.HasArraySort = true
+		s.ArraySortFunc = fn
+	}
+}
+
+// WithAuthorize limits the field to requests the predicate allows, other requests
+// don't get the column, can't sort or search on it and it is left out of the SELECT
+func WithAuthorize(fn func(r *http.Request) bool) FieldOption {
+	return func(s *Field) {
+		s.Authorize = fn
+	}
+}
+
+// WithMask keeps a field rejected by WithAuthorize as a column and replaces its values
+// in the records, it still can't be sorted or searched on. Struct fields the masked
+// value can't be assigned to are set to their zero value
+func WithMask(fn func(value any) any) FieldOption {
+	return func(s *Field) {
+		s.Mask = fn
+	}
+}
+
+// WithNullsFirst sorts NULL values of the field first, whatever the direction
+func WithNullsFirst() FieldOption {
+	return func(s *Field) {
+		s.Nulls = NullsFirst
+	}
+}
+
+// WithNullsLast sorts NULL values of the field last, whatever the direction
+func WithNullsLast() FieldOption {
+	return func(s *Field) {
+		s.Nulls = NullsLast
+	}
+}
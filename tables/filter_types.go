@@ -0,0 +1,189 @@
+package tables
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dateLayouts lists the accepted date filter formats, date only values
+// are treated as whole days
+var dateLayouts = []string{time.DateOnly, time.RFC3339}
+
+// NewDateRangeFilter creates a filter matching rows between two dates given as "from,to",
+// either bound may be left empty
+func NewDateRangeFilter(name string, opts ...FilterOpt) *Filter {
+	f := NewFilter(name, WithComponent("date-range"))
+	f.query = func(db *gorm.DB, value string) error {
+		from, to, err := splitRange(f.Field, value)
+		if err != nil {
+			return err
+		}
+
+		var (
+			fromTime, toTime time.Time
+			dateOnly         bool
+		)
+		if from != "" {
+			if fromTime, _, err = parseDate(from); err != nil {
+				return &ValidationError{Param: "filter", Key: f.Field, Reason: err.Error()}
+			}
+		}
+		if to != "" {
+			if toTime, dateOnly, err = parseDate(to); err != nil {
+				return &ValidationError{Param: "filter", Key: f.Field, Reason: err.Error()}
+			}
+			if from != "" && toTime.Before(fromTime) {
+				return &ValidationError{Param: "filter", Key: f.Field, Reason: "range end is before start"}
+			}
+		}
+
+		if from != "" {
+			db.Where(f.Field+" >= ?", fromTime)
+		}
+		if to != "" && dateOnly {
+			// Include the whole final day
+			db.Where(f.Field+" < ?", toTime.AddDate(0, 0, 1))
+		} else if to != "" {
+			db.Where(f.Field+" <= ?", toTime)
+		}
+		return nil
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// NewNumberRangeFilter creates a filter matching rows between two numbers given as "min,max",
+// either bound may be left empty
+func NewNumberRangeFilter(name string, opts ...FilterOpt) *Filter {
+	f := NewFilter(name, WithComponent("number-range"))
+	f.query = func(db *gorm.DB, value string) error {
+		from, to, err := splitRange(f.Field, value)
+		if err != nil {
+			return err
+		}
+
+		var lower, upper float64
+		if from != "" {
+			if lower, err = strconv.ParseFloat(from, 64); err != nil {
+				return &ValidationError{Param: "filter", Key: f.Field, Reason: "invalid number " + strconv.Quote(from)}
+			}
+		}
+		if to != "" {
+			if upper, err = strconv.ParseFloat(to, 64); err != nil {
+				return &ValidationError{Param: "filter", Key: f.Field, Reason: "invalid number " + strconv.Quote(to)}
+			}
+			if from != "" && upper < lower {
+				return &ValidationError{Param: "filter", Key: f.Field, Reason: "range end is before start"}
+			}
+		}
+
+		if from != "" {
+			db.Where(f.Field+" >= ?", lower)
+		}
+		if to != "" {
+			db.Where(f.Field+" <= ?", upper)
+		}
+		return nil
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// NewBooleanFilter creates a toggle filter matching rows where the column equals the given boolean
+func NewBooleanFilter(name string, opts ...FilterOpt) *Filter {
+	f := NewFilter(name, WithComponent("boolean"))
+	f.query = func(db *gorm.DB, value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return &ValidationError{Param: "filter", Key: f.Field, Reason: "expects a boolean"}
+		}
+		db.Where(f.Field+" = ?", v)
+		return nil
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// NewSelectFilter creates a filter matching rows equal to one of the given options
+func NewSelectFilter(name string, options []FilterOptions, opts ...FilterOpt) *Filter {
+	f := NewFilter(name, WithComponent("select"), WithOptions(options...))
+	f.query = func(db *gorm.DB, value string) error {
+		v, err := f.optionValue(value)
+		if err != nil {
+			return err
+		}
+		db.Where(f.Field+" = ?", v)
+		return nil
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// NewMultiSelectFilter creates a filter matching rows in a comma separated list of the given options
+func NewMultiSelectFilter(name string, options []FilterOptions, opts ...FilterOpt) *Filter {
+	f := NewFilter(name, WithComponent("multi-select"), WithOptions(options...))
+	f.query = func(db *gorm.DB, value string) error {
+		var values []any
+		for _, raw := range strings.Split(value, ",") {
+			if raw = strings.TrimSpace(raw); raw == "" {
+				continue
+			}
+			v, err := f.optionValue(raw)
+			if err != nil {
+				return err
+			}
+			values = append(values, v)
+		}
+		if len(values) == 0 {
+			return &ValidationError{Param: "filter", Key: f.Field, Reason: "expects at least one option"}
+		}
+		db.Where(f.Field+" IN ?", values)
+		return nil
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// optionValue returns the typed value of the option matching the raw request value
+func (f *Filter) optionValue(raw string) (any, error) {
+	for _, o := range f.Options {
+		if fmt.Sprint(o.Value) == raw {
+			return o.Value, nil
+		}
+	}
+	return nil, &ValidationError{Param: "filter", Key: f.Field, Reason: "unknown option " + strconv.Quote(raw)}
+}
+
+// splitRange splits a "from,to" range value requiring at least one bound
+func splitRange(field, value string) (string, string, error) {
+	from, to, ok := strings.Cut(value, ",")
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if !ok || (from == "" && to == "") {
+		return "", "", &ValidationError{Param: "filter", Key: field, Reason: "expects a from,to range"}
+	}
+	return from, to, nil
+}
+
+// parseDate parses a date filter value and reports whether it was date only
+func parseDate(value string) (time.Time, bool, error) {
+	for i, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, i == 0, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,98 @@
+package tables
+
+import (
+	"database/sql/driver"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var statusOptions = []FilterOptions{
+	{Label: "Open", Value: "open"},
+	{Label: "Closed", Value: "closed"},
+	{Label: "Archived", Value: 3},
+}
+
+func TestTypedFilters(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	day := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+
+	tests := []struct {
+		filter *Filter
+		value  string
+		sql    string
+		args   []driver.Value
+	}{
+		{NewDateRangeFilter("Created at"), "2024-01-01,2024-01-31", `WHERE created_at >= $1 AND created_at < $2`, []driver.Value{day("2024-01-01"), day("2024-02-01")}},
+		{NewDateRangeFilter("Created at"), ",2024-01-31T10:00:00Z", `WHERE created_at <= $1`, []driver.Value{time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)}},
+		{NewDateRangeFilter("Created at"), "2024-01-01,", `WHERE created_at >= $1`, []driver.Value{day("2024-01-01")}},
+		{NewNumberRangeFilter("Price"), "10,20.5", `WHERE price >= $1 AND price <= $2`, []driver.Value{float64(10), 20.5}},
+		{NewNumberRangeFilter("Price"), ",20", `WHERE price <= $1`, []driver.Value{float64(20)}},
+		{NewBooleanFilter("Active"), "true", `WHERE active = $1`, []driver.Value{true}},
+		{NewSelectFilter("Status", statusOptions), "3", `WHERE status = $1`, []driver.Value{3}},
+		{NewMultiSelectFilter("Status", statusOptions), "open,closed", `WHERE status IN ($1,$2)`, []driver.Value{"open", "closed"}},
+	}
+
+	for _, tt := range tests {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "orders" ` + tt.sql)).
+			WithArgs(tt.args...).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		var res []map[string]interface{}
+		d := db.Table("orders")
+		tt.filter.Value = tt.value
+		is.Nil(tt.filter.ApplyQuery(d))
+		d.Find(&res)
+	}
+
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestTypedFiltersInvalid(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, _ := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	tests := []struct {
+		filter *Filter
+		value  string
+	}{
+		{NewDateRangeFilter("Created at"), "2024-01-01"},
+		{NewDateRangeFilter("Created at"), ","},
+		{NewDateRangeFilter("Created at"), "yesterday,"},
+		{NewDateRangeFilter("Created at"), "2024-02-01,2024-01-01"},
+		{NewNumberRangeFilter("Price"), "ten,20"},
+		{NewNumberRangeFilter("Price"), "20,10"},
+		{NewBooleanFilter("Active"), "maybe"},
+		{NewSelectFilter("Status", statusOptions), "pending"},
+		{NewMultiSelectFilter("Status", statusOptions), "open,pending"},
+		{NewMultiSelectFilter("Status", statusOptions), ","},
+	}
+
+	for _, tt := range tests {
+		var verr *ValidationError
+		tt.filter.Value = tt.value
+		is.ErrorAs(tt.filter.ApplyQuery(db.Table("orders")), &verr, tt.value)
+	}
+}
+
+func TestTypedFilterComponents(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal("date-range", NewDateRangeFilter("Created at").Component)
+	is.Equal("number-range", NewNumberRangeFilter("Price").Component)
+	is.Equal("boolean", NewBooleanFilter("Active").Component)
+	is.Equal("select", NewSelectFilter("Status", statusOptions).Component)
+	is.Equal("multi-select", NewMultiSelectFilter("Status", statusOptions).Component)
+	is.Equal("created", NewDateRangeFilter("Created at", WithField("created")).Field)
+	is.Len(NewMultiSelectFilter("Status", statusOptions).Options, 3)
+}
@@ -1,6 +1,7 @@
 package tables
 
 import (
+	"slices"
 	"strconv"
 
 	"github.com/humweb/go-tables/utils"
@@ -9,11 +10,19 @@ import (
 
 // Filter defines filters for adding query clauses to our query
 type Filter struct {
-	Component string          `json:"component"`
-	Label     string          `json:"label"`
-	Field     string          `json:"field"`
-	Options   []FilterOptions `json:"options"`
-	Value     string          `json:"value"`
+	Component string            `json:"component"`
+	Label     string            `json:"label"`
+	Field     string            `json:"field"`
+	Options   []FilterOptions   `json:"options"`
+	Value     string            `json:"value"`
+	Operator  Operator          `json:"operator,omitempty"`
+	Operators []Operator        `json:"operators,omitempty"`
+	Values    map[string]string `json:"values,omitempty"`
+
+	// query replaces the default criteria for typed and custom filters
+	query FilterQuery
+	// scope limits the related rows of relation filters
+	scope func(*gorm.DB) *gorm.DB
 }
 
 // FilterOptions defines filter options
@@ -23,14 +32,39 @@ type FilterOptions struct {
 }
 
 // ApplyQuery adds search criteria to the database query
-func (f *Filter) ApplyQuery(db *gorm.DB) {
+// Filters without an operator use "=" for integers and a case insensitive match for everything else
+func (f *Filter) ApplyQuery(db *gorm.DB) error {
+	if f.query != nil {
+		return f.query(db, f.Value)
+	}
+	if f.Operator != "" {
+		return applyOperator(db, f.Field, f.Operator, f.Value)
+	}
 	if v, err := strconv.Atoi(f.Value); err == nil {
 		db.Where(f.Field+" = ?", v)
 	} else {
-		db.Where(f.Field+" ILIKE ?", "%"+f.Value+"%")
+		db.Where(DialectOf(db).Contains(f.Field), "%"+f.Value+"%")
+	}
+	return nil
+}
+
+// ApplyOperator adds criteria to the database query using a client selected operator
+func (f *Filter) ApplyOperator(db *gorm.DB, op Operator, value string) error {
+	if !f.Allows(op) {
+		return &ValidationError{Param: "filter", Key: f.Field + "[" + string(op) + "]", Reason: "operator not allowed"}
 	}
+	return applyOperator(db, f.Field, op, value)
 }
 
+// Allows reports whether the client may apply the operator to this filter
+func (f *Filter) Allows(op Operator) bool {
+	return op.IsValid() && (op == f.Operator || slices.Contains(f.Operators, op))
+}
+
+// FilterQuery applies a filter value to the database query, it is used for
+// filters that don't map to a single column
+type FilterQuery func(db *gorm.DB, value string) error
+
 // FilterOpt is an optional function type to set filter attributes
 type FilterOpt func(*Filter)
 
@@ -63,6 +97,27 @@ func WithComponent(name string) FilterOpt {
 	}
 }
 
+// WithOperator sets the operator used when the filter is given a plain value
+func WithOperator(op Operator) FilterOpt {
+	return func(s *Filter) {
+		s.Operator = op
+	}
+}
+
+// WithOperators sets which operators the client may choose with filters[field][op]=value
+func WithOperators(ops ...Operator) FilterOpt {
+	return func(s *Filter) {
+		s.Operators = ops
+	}
+}
+
+// WithQuery replaces the default column criteria with a custom query callback
+func WithQuery(fn FilterQuery) FilterOpt {
+	return func(s *Filter) {
+		s.query = fn
+	}
+}
+
 // WithOptions allows you to set options for required filter types (select)
 func WithOptions(options ...FilterOptions) FilterOpt {
 	return func(s *Filter) {
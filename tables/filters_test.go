@@ -1,9 +1,12 @@
 package tables
 
 import (
+	"database/sql/driver"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/humweb/go-tables/testutils"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"regexp"
 	"testing"
 )
 
@@ -63,6 +66,87 @@ func TestApplyQueryNumeric(t *testing.T) {
 	is.Nil(mock.ExpectationsWereMet())
 }
 
+func TestApplyOperator(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	tests := []struct {
+		op    Operator
+		value string
+		sql   string
+		args  []driver.Value
+	}{
+		{OpEq, "5", `WHERE price = $1`, []driver.Value{5}},
+		{OpNeq, "foo", `WHERE price <> $1`, []driver.Value{"foo"}},
+		{OpGt, "5", `WHERE price > $1`, []driver.Value{5}},
+		{OpGte, "5", `WHERE price >= $1`, []driver.Value{5}},
+		{OpLt, "5", `WHERE price < $1`, []driver.Value{5}},
+		{OpLte, "5", `WHERE price <= $1`, []driver.Value{5}},
+		{OpIn, "1,2, 3", `WHERE price IN ($1,$2,$3)`, []driver.Value{1, 2, 3}},
+		{OpBetween, "1,9", `WHERE price BETWEEN $1 AND $2`, []driver.Value{1, 9}},
+		{OpNull, "true", `WHERE price IS NULL`, nil},
+		{OpNull, "false", `WHERE price IS NOT NULL`, nil},
+		{OpContains, "foo", `WHERE price ILIKE $1`, []driver.Value{"%foo%"}},
+	}
+
+	for _, tt := range tests {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "products" ` + tt.sql)).
+			WithArgs(tt.args...).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		f := NewFilter("Price", WithOperators(tt.op))
+
+		var res []map[string]interface{}
+		d := db.Table("products")
+		is.Nil(f.ApplyOperator(d, tt.op, tt.value))
+		d.Find(&res)
+	}
+
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestApplyOperatorInvalid(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, _ := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	f := NewFilter("Price", WithOperator(OpEq), WithOperators(OpBetween, OpNull, OpIn))
+
+	var verr *ValidationError
+	is.ErrorAs(f.ApplyOperator(db, OpGt, "1"), &verr)
+	is.Equal("price[gt]", verr.Key)
+	is.ErrorAs(f.ApplyOperator(db, OpBetween, "1"), &verr)
+	is.ErrorAs(f.ApplyOperator(db, OpNull, "maybe"), &verr)
+	is.ErrorAs(f.ApplyOperator(db, OpIn, " , "), &verr)
+	is.ErrorAs(f.ApplyOperator(db, Operator("drop"), "1"), &verr)
+	is.True(f.Allows(OpEq))
+}
+
+func TestApplyCustomQuery(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	f := NewFilter("Overdue", WithQuery(func(db *gorm.DB, value string) error {
+		if value == "1" {
+			db.Where("EXISTS (SELECT 1 FROM invoices WHERE invoices.user_id = users.id AND invoices.due_at < NOW())")
+		}
+		return nil
+	}))
+	f.Value = "1"
+
+	expectedSQL := regexp.QuoteMeta(`SELECT * FROM "users" WHERE EXISTS (SELECT 1 FROM invoices WHERE invoices.user_id = users.id AND invoices.due_at < NOW())`)
+	mock.ExpectQuery(expectedSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var res []map[string]interface{}
+	d := db.Table("users")
+	is.Nil(f.ApplyQuery(d))
+	d.Find(&res)
+
+	is.Nil(mock.ExpectationsWereMet())
+}
+
 func TestNewFilter(t *testing.T) {
 	is := assert.New(t)
 
@@ -0,0 +1,90 @@
+package tables
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTextSearchConfig is the PostgreSQL text search configuration used by full text search
+const DefaultTextSearchConfig = "simple"
+
+// FullTextSearch configures PostgreSQL full text matching for global search
+type FullTextSearch struct {
+	// Config is the text search configuration such as "english"
+	Config string
+	// Columns are combined into the searched document, they default to the searchable fields
+	// the request may see. Columns of denied or masked fields are dropped, other columns must
+	// not hold restricted data since search terms match against them
+	Columns []string
+	// Vector is a stored tsvector column searched instead of Columns, it must not include
+	// restricted data
+	Vector string
+	// Rank orders results by ts_rank when the request has no sort
+	Rank bool
+}
+
+// config returns the text search configuration as a quoted literal, a literal keeps
+// the expression matching expression indexes
+func (s *FullTextSearch) config() string {
+	if s.Config == "" {
+		return "'" + DefaultTextSearchConfig + "'"
+	}
+	return "'" + strings.ReplaceAll(s.Config, "'", "''") + "'"
+}
+
+// document returns the tsvector expression searched for the resource, it is empty when
+// the request may not search any column
+func (r *AbstractResource) document() string {
+	s := r.FullTextSearch
+	if s.Vector != "" {
+		return s.Vector
+	}
+
+	var columns []string
+	if s.Columns == nil {
+		for _, f := range r.Fields {
+			if f.Searchable && f.allowed(r.Request) && !strings.Contains(f.Attribute, ".") {
+				columns = append(columns, f.Attribute)
+			}
+		}
+	}
+	for _, c := range s.Columns {
+		if !r.restricted(c) {
+			columns = append(columns, c)
+		}
+	}
+
+	if len(columns) == 0 {
+		return ""
+	}
+
+	parts := make([]string, len(columns))
+	for i, c := range columns {
+		parts[i] = "coalesce(" + c + "::text, '')"
+	}
+	return "to_tsvector(" + s.config() + ", " + strings.Join(parts, " || ' ' || ") + ")"
+}
+
+// query returns the tsquery expression for a search value
+func (s *FullTextSearch) query() string {
+	return "websearch_to_tsquery(" + s.config() + ", ?)"
+}
+
+// applyFullTextSearch matches the global search value against the resource's document,
+// nothing matches without searchable columns. Databases whose dialect doesn't support
+// full text search return ErrUnsupported
+func (r *AbstractResource) applyFullTextSearch(q *gorm.DB, value string) error {
+	if !DialectOf(q).FullTextSearch() {
+		return fmt.Errorf("%w: full text search needs PostgreSQL, not %s", ErrUnsupported, q.Dialector.Name())
+	}
+
+	document := r.document()
+	if document == "" {
+		q.Where("1 = 0")
+		return nil
+	}
+	q.Where(document+" @@ "+r.FullTextSearch.query(), value)
+	return nil
+}
@@ -0,0 +1,110 @@
+package tables
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestFullTextSearch(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?search[global]=john%20-smith", nil)
+	res := NewUserResource(db, request)
+	res.FullTextSearch = &FullTextSearch{Rank: true}
+
+	document := `to_tsvector('simple', coalesce(id::text, '') || ' ' || coalesce(last_name::text, ''))`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE ` + document + ` @@ websearch_to_tsquery('simple', $1)`)).
+		WithArgs("john -smith").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY ts_rank(`+document+`, websearch_to_tsquery('simple', $2)) DESC, id ASC LIMIT $3`)).
+		WithArgs("john -smith", "john -smith", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestFullTextSearchVector(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?search[global]=john&sort=-last_name", nil)
+	res := NewUserResource(db, request)
+	res.FullTextSearch = &FullTextSearch{Config: "english", Vector: "search_vector", Rank: true}
+
+	where := `WHERE search_vector @@ websearch_to_tsquery('english', $1)`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" ` + where)).
+		WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" `+where+` ORDER BY last_name DESC LIMIT $2`)).
+		WithArgs("john", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestFullTextSearchWithoutColumns(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?search[global]=john", nil)
+	res := NewUserResource(db, request)
+	res.FullTextSearch = &FullTextSearch{Rank: true}
+
+	deny := func(r *http.Request) bool { return false }
+	res.Fields[0] = NewField("ID", WithSortable(), WithSearchable(), WithAuthorize(deny))
+	res.Fields[2] = NewField("Last name", WithSortable(), WithSearchable(), WithAuthorize(deny))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE 1 = 0`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE 1 = 0 ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestFullTextSearchRestrictedColumns(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?search[global]=john", nil)
+	res := NewUserResource(db, request)
+	res.FullTextSearch = &FullTextSearch{Columns: []string{"username", "last_name"}}
+
+	deny := func(r *http.Request) bool { return false }
+	res.Fields[2] = NewField("Last name", WithSearchable(), WithAuthorize(deny))
+
+	where := `WHERE to_tsvector('simple', coalesce(username::text, '')) @@ websearch_to_tsquery('simple', $1)`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" ` + where)).
+		WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(where+` ORDER BY id ASC LIMIT $2`)).
+		WithArgs("john", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
@@ -0,0 +1,102 @@
+package tables
+
+import (
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Operator defines how a filter value is compared against a column
+type Operator string
+
+const (
+	OpEq       Operator = "eq"
+	OpNeq      Operator = "neq"
+	OpGt       Operator = "gt"
+	OpGte      Operator = "gte"
+	OpLt       Operator = "lt"
+	OpLte      Operator = "lte"
+	OpIn       Operator = "in"
+	OpBetween  Operator = "between"
+	OpNull     Operator = "null"
+	OpContains Operator = "contains"
+)
+
+var comparisons = map[Operator]string{
+	OpEq:  " = ?",
+	OpNeq: " <> ?",
+	OpGt:  " > ?",
+	OpGte: " >= ?",
+	OpLt:  " < ?",
+	OpLte: " <= ?",
+}
+
+// IsValid reports whether the operator is known
+func (o Operator) IsValid() bool {
+	switch o {
+	case OpIn, OpBetween, OpNull, OpContains:
+		return true
+	}
+	_, ok := comparisons[o]
+	return ok
+}
+
+// applyOperator adds a where clause comparing field to value using the given operator.
+// List values (in, between) are comma separated
+func applyOperator(db *gorm.DB, field string, op Operator, value string) error {
+	if clause, ok := comparisons[op]; ok {
+		db.Where(field+clause, operand(value))
+		return nil
+	}
+
+	switch op {
+	case OpIn:
+		values := splitValues(value)
+		if len(values) == 0 {
+			return &ValidationError{Param: "filter", Key: field, Reason: "in expects at least one value"}
+		}
+		db.Where(field+" IN ?", values)
+	case OpBetween:
+		values := splitValues(value)
+		if len(values) != 2 {
+			return &ValidationError{Param: "filter", Key: field, Reason: "between expects two values"}
+		}
+		db.Where(field+" BETWEEN ? AND ?", values[0], values[1])
+	case OpNull:
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return &ValidationError{Param: "filter", Key: field, Reason: "null expects a boolean"}
+		}
+		if isNull {
+			db.Where(field + " IS NULL")
+		} else {
+			db.Where(field + " IS NOT NULL")
+		}
+	case OpContains:
+		db.Where(DialectOf(db).Contains(field), "%"+value+"%")
+	default:
+		return &ValidationError{Param: "filter", Key: field, Reason: "unknown operator " + string(op)}
+	}
+
+	return nil
+}
+
+// operand converts integer values so they are compared numerically
+func operand(value string) any {
+	if v, err := strconv.Atoi(value); err == nil {
+		return v
+	}
+	return value
+}
+
+// splitValues splits a comma separated list into query operands
+func splitValues(value string) []any {
+	var values []any
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, operand(v))
+		}
+	}
+	return values
+}
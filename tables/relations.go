@@ -0,0 +1,281 @@
+package tables
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// derivedColumns collects the columns added to the model's table for a request,
+// count subqueries and the columns of joined relations
+type derivedColumns struct {
+	selects  []string
+	args     []any
+	joins    []string
+	joinArgs []any
+}
+
+// wrap replaces the model's table with a derived table of the same name holding the
+// collected columns. Joins happen inside the derived table, so the model's own columns
+// stay unambiguous for filters, ApplyFilter and WithGlobalSearch
+func (d *derivedColumns) wrap(q *gorm.DB) {
+	if len(d.selects) == 0 {
+		return
+	}
+
+	table := DialectOf(q).Quote(q.Statement.Schema.Table)
+	selects := append([]string{table + ".*"}, d.selects...)
+	inner := q.Session(&gorm.Session{NewDB: true}).Model(q.Statement.Model).Select(strings.Join(selects, ", "), d.args...)
+	if len(d.joins) > 0 {
+		inner = inner.Joins(strings.Join(d.joins, " "), d.joinArgs...)
+	}
+	q.Table("(?) AS "+table, inner)
+}
+
+// joinRelations LEFT JOINs the relations referenced by dotted sort and search attributes
+// such as "client.title". Joins are aliased by the attribute's first segment and the
+// column is added to the derived table as "client__title". Only belongs to and has one
+// relations can be joined, other attributes are a mistake in the resource's fields and
+// return ErrConfiguration
+func (r *AbstractResource) joinRelations(q *gorm.DB, sort string, d *derivedColumns) error {
+	r.relationColumns = nil
+	dialect := DialectOf(q)
+
+	type use struct{ param, attribute string }
+	var uses []use
+
+	keys := make([]string, 0, len(r.TableRequest.Search))
+	for key := range r.TableRequest.Search {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		uses = append(uses, use{"search", key})
+	}
+	for _, c := range parseSort(sort) {
+		uses = append(uses, use{"sort", c.Column})
+	}
+
+	joined := make(map[string]bool)
+	for _, u := range uses {
+		head, _, nested := strings.Cut(u.attribute, ".")
+		if _, ok := r.relationColumns[u.attribute]; !nested || ok {
+			continue
+		}
+
+		if !joined[head] {
+			if err := q.Statement.Parse(q.Statement.Model); err != nil {
+				return fmt.Errorf("%w: field %q needs a model schema: %w", ErrConfiguration, u.attribute, err)
+			}
+			sch := q.Statement.Schema
+
+			rel := relation(sch, head)
+			if rel == nil || (rel.Type != schema.BelongsTo && rel.Type != schema.HasOne) {
+				return fmt.Errorf("%w: field %q is not on a belongs to or has one relation", ErrConfiguration, u.attribute)
+			}
+
+			on, args := relationConditions(dialect, rel, head, sch.Table)
+			d.joins = append(d.joins, fmt.Sprintf("LEFT JOIN %s %s ON %s",
+				dialect.Quote(rel.FieldSchema.Table), dialect.Quote(head), strings.Join(on, " AND ")))
+			d.joinArgs = append(d.joinArgs, args...)
+			joined[head] = true
+		}
+
+		if r.relationColumns == nil {
+			r.relationColumns = make(map[string]string)
+		}
+		column := dialect.Quote(strings.ReplaceAll(u.attribute, ".", "__"))
+		d.selects = append(d.selects, dialect.Quote(u.attribute)+" AS "+column)
+		r.relationColumns[u.attribute] = column
+	}
+	return nil
+}
+
+// columnOf returns the column an attribute is queried by, attributes of joined relations
+// use their column in the derived table
+func (r *AbstractResource) columnOf(attribute string) string {
+	if column, ok := r.relationColumns[attribute]; ok {
+		return column
+	}
+	return attribute
+}
+
+// relationPreloads returns the relations of dotted field attributes that aren't preloaded yet
+func (r *AbstractResource) relationPreloads(q *gorm.DB) []string {
+	if err := q.Statement.Parse(q.Statement.Model); err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, f := range r.Fields {
+		head, _, nested := strings.Cut(f.Attribute, ".")
+		if !nested || f.Actions != nil || f.denied(r.Request) {
+			continue
+		}
+
+		rel := relation(q.Statement.Schema, head)
+		if rel == nil || slices.Contains(names, rel.Name) || slices.ContainsFunc(r.Preloads, func(p Preload) bool {
+			return p.Name == rel.Name
+		}) {
+			continue
+		}
+		names = append(names, rel.Name)
+	}
+	return names
+}
+
+// relationConditions returns the conditions linking a belongs to, has one or has many
+// relation's rows, referenced by alias, to the model's table with quoted identifiers
+func relationConditions(d Dialect, rel *schema.Relationship, alias, table string) ([]string, []any) {
+	var (
+		on   []string
+		args []any
+	)
+	for _, ref := range rel.References {
+		switch {
+		case ref.PrimaryKey == nil:
+			on = append(on, d.Quote(alias+"."+ref.ForeignKey.DBName)+" = ?")
+			args = append(args, ref.PrimaryValue)
+		case ref.OwnPrimaryKey:
+			on = append(on, d.Quote(alias+"."+ref.ForeignKey.DBName)+" = "+d.Quote(table+"."+ref.PrimaryKey.DBName))
+		default:
+			on = append(on, d.Quote(alias+"."+ref.PrimaryKey.DBName)+" = "+d.Quote(table+"."+ref.ForeignKey.DBName))
+		}
+	}
+	return on, args
+}
+
+// relationSubquery selects from a relation's table where rows belong to the current row
+// of the model's table, many to many relations go through their join table. The related
+// model's query scopes apply, so soft deleted rows are left out
+func relationSubquery(db *gorm.DB, sch *schema.Schema, rel *schema.Relationship, scope func(*gorm.DB) *gorm.DB, columns string) *gorm.DB {
+	d := DialectOf(db)
+	table := rel.FieldSchema.Table
+	model := reflect.New(rel.FieldSchema.ModelType).Interface()
+	sub := db.Session(&gorm.Session{NewDB: true}).Model(model).Table(table).Select(columns)
+
+	if rel.JoinTable == nil {
+		on, args := relationConditions(d, rel, table, sch.Table)
+		sub.Where(strings.Join(on, " AND "), args...)
+	} else {
+		join := rel.JoinTable.Table
+		var on []string
+		for _, ref := range rel.References {
+			switch {
+			case ref.PrimaryKey == nil:
+				sub.Where(d.Quote(join+"."+ref.ForeignKey.DBName)+" = ?", ref.PrimaryValue)
+			case ref.OwnPrimaryKey:
+				sub.Where(d.Quote(join+"."+ref.ForeignKey.DBName) + " = " + d.Quote(sch.Table+"."+ref.PrimaryKey.DBName))
+			default:
+				on = append(on, d.Quote(join+"."+ref.ForeignKey.DBName)+" = "+d.Quote(table+"."+ref.PrimaryKey.DBName))
+			}
+		}
+		sub.Joins("JOIN " + d.Quote(join) + " ON " + strings.Join(on, " AND "))
+	}
+
+	if scope != nil {
+		sub = scope(sub)
+	}
+	return sub
+}
+
+// modelRelation parses the query's model and finds one of its relations
+func modelRelation(db *gorm.DB, name string) (*schema.Schema, *schema.Relationship, error) {
+	if err := db.Statement.Parse(db.Statement.Model); err != nil {
+		return nil, nil, err
+	}
+	rel := relation(db.Statement.Schema, name)
+	if rel == nil {
+		return nil, nil, fmt.Errorf("tables: %s has no relation %q", db.Statement.Schema.Name, name)
+	}
+	return db.Statement.Schema, rel, nil
+}
+
+// NewHasFilter creates a toggle filter matching rows with ("1") or without ("0") related
+// rows of a model relation, WithRelationScope limits the related rows that count
+func NewHasFilter(name, relation string, opts ...FilterOpt) *Filter {
+	f := NewFilter(name, WithComponent("boolean"))
+	f.query = func(db *gorm.DB, value string) error {
+		exists, err := strconv.ParseBool(value)
+		if err != nil {
+			return &ValidationError{Param: "filter", Key: f.Field, Reason: "expects a boolean"}
+		}
+
+		sch, rel, err := modelRelation(db, relation)
+		if err != nil {
+			return err
+		}
+
+		sub := relationSubquery(db, sch, rel, f.scope, "1")
+		if exists {
+			db.Where("EXISTS (?)", sub)
+		} else {
+			db.Where("NOT EXISTS (?)", sub)
+		}
+		return nil
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// WithRelationScope adds conditions on the related rows of a has filter
+func WithRelationScope(scope func(*gorm.DB) *gorm.DB) FilterOpt {
+	return func(f *Filter) {
+		f.scope = scope
+	}
+}
+
+// NewCountField creates a field showing the number of related rows of a model relation
+// as "<relation>_count", WithCountScope limits the related rows that are counted
+func NewCountField(name, relation string, opts ...FieldOption) *Field {
+	f := NewField(name, WithAttribute(schema.NamingStrategy{}.ColumnName("", relation)+"_count"))
+	f.CountRelation = relation
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// WithCountScope adds conditions on the related rows of a count field
+func WithCountScope(scope func(*gorm.DB) *gorm.DB) FieldOption {
+	return func(s *Field) {
+		s.CountScope = scope
+	}
+}
+
+// withCounts adds the count fields used by the request to the derived table,
+// so count columns can be used like any other column
+func (r *AbstractResource) withCounts(q *gorm.DB, sort string, d *derivedColumns) error {
+	r.FlagVisibility()
+	sorted := parseSort(sort)
+
+	for _, f := range r.Fields {
+		if f.CountRelation == "" || f.denied(r.Request) {
+			continue
+		}
+
+		_, filtered := r.TableRequest.Filters[f.Attribute]
+		_, filteredOp := r.TableRequest.FilterOps[f.Attribute]
+		used := f.Visible || filtered || filteredOp || slices.ContainsFunc(sorted, func(c sortColumn) bool {
+			return c.Column == f.Attribute
+		})
+		if !used {
+			continue
+		}
+
+		sch, rel, err := modelRelation(q, f.CountRelation)
+		if err != nil {
+			return err
+		}
+		d.selects = append(d.selects, "(?) AS "+DialectOf(q).Quote(f.Attribute))
+		d.args = append(d.args, relationSubquery(q, sch, rel, f.CountScope, "COUNT(*)"))
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package tables
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func newClientUserResource(t *testing.T, url string) (*UserResource, sqlmock.Sqlmock, func() error) {
+	sqlDB, db, mock := testutils.DBMock(t)
+	request, _ := http.NewRequest(http.MethodGet, url, nil)
+	res := NewUserResource(db, request)
+	res.Fields = append(res.Fields, NewField("Client", WithAttribute("client.title"), WithSortable(), WithSearchable()))
+	return res, mock, sqlDB.Close
+}
+
+func TestRelationColumns(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newClientUserResource(t, "/users?search[client.title]=acme&sort=-client.title")
+	defer closeDB()
+
+	join := `FROM (SELECT "users".*, "client"."title" AS "client__title" FROM "users" LEFT JOIN "clients" "client" ON "client"."id" = "users"."client_id") AS "users" WHERE "client__title" ILIKE $1`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) ` + join)).
+		WithArgs("%acme%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * `+join+` ORDER BY "client__title" DESC LIMIT $2`)).
+		WithArgs("%acme%", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client_id"}).AddRow(1, 2))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "clients" WHERE "clients"."id" = $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(2, "acme"))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Equal("acme", pathValue(resp["records"].([]UserPrivate)[0], "client.title"))
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestRelationColumnsDenied(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newClientUserResource(t, "/users")
+	defer closeDB()
+	res.Fields[len(res.Fields)-1] = NewField("Client", WithAttribute("client.title"), WithAuthorize(func(r *http.Request) bool {
+		return false
+	}))
+	res.Preloads = []Preload{{Name: "Client"}}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client_id"}).AddRow(1, 2))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "clients" WHERE "clients"."id" = $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(2, "acme"))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+
+	client := resp["records"].([]UserPrivate)[0].Client
+	is.Equal(uint(2), client.ID)
+	is.Empty(client.Title)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestRelationColumnsCursor(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newClientUserResource(t, "/users?sort=client.title&perPage=1")
+	defer closeDB()
+	res.PaginationMode = CursorPagination
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM (SELECT "users".*, "client"."title" AS "client__title" FROM "users" LEFT JOIN "clients" "client" ON "client"."id" = "users"."client_id") AS "users" ORDER BY "client__title" ASC,id ASC LIMIT $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client_id"}).AddRow(1, 2).AddRow(3, 4))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "clients" WHERE "clients"."id" IN ($1,$2)`)).
+		WithArgs(2, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(2, "acme").AddRow(4, "beta"))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+
+	cursor, err := DecodeCursor(resp["pagination"].(Pagination).NextCursor)
+	is.Nil(err)
+	is.Equal([]any{"acme", int64(1)}, cursor.Values)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestRelationColumnsWithFilters(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newClientUserResource(t, "/users?filters[id]=1&search[client.title]=x")
+	defer closeDB()
+
+	// The model's columns stay unambiguous for filters and the resource's global search
+	derived := `FROM (SELECT "users".*, "client"."title" AS "client__title" FROM "users" LEFT JOIN "clients" "client" ON "client"."id" = "users"."client_id") AS "users" `
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) `+derived+`WHERE "client__title" ILIKE $1 AND id = $2`)).
+		WithArgs("%x%", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * `+derived+`WHERE "client__title" ILIKE $1 AND id = $2 ORDER BY id ASC LIMIT $3`)).
+		WithArgs("%x%", 1, 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+
+	res.Request, _ = http.NewRequest(http.MethodGet, "/users?search[global]=5&sort=client.title", nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) ` + derived + `WHERE id = $1`)).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * `+derived+`WHERE id = $1 ORDER BY "client__title" ASC LIMIT $2`)).
+		WithArgs(5, 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestRelationColumnsInvalid(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newClientUserResource(t, "/users?sort=site.title")
+	defer closeDB()
+	res.Fields = append(res.Fields, NewField("Site", WithAttribute("site.title"), WithSortable()))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+
+	is.ErrorIs(err, ErrConfiguration)
+	is.NotErrorIs(err, ErrValidation)
+	is.Contains(err.Error(), "site.title")
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+type Site struct {
+	ID     uint `json:"id"`
+	UserID uint `json:"user_id"`
+	Active bool `json:"active"`
+}
+
+type Tag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type SiteOwner struct {
+	ID         uint   `json:"id"`
+	Sites      []Site `gorm:"foreignKey:UserID" json:"sites"`
+	Tags       []Tag  `gorm:"many2many:user_tags;joinForeignKey:UserID" json:"tags"`
+	SitesCount int    `gorm:"->;-:migration" json:"sites_count"`
+}
+
+func (SiteOwner) TableName() string {
+	return "users"
+}
+
+func newSiteOwnerResource(t *testing.T, url string) (*UserResource, sqlmock.Sqlmock, func() error) {
+	sqlDB, db, mock := testutils.DBMock(t)
+	request, _ := http.NewRequest(http.MethodGet, url, nil)
+	res := NewUserResource(db, request)
+	res.Fields = []*Field{
+		NewField("ID", WithSortable()),
+		NewCountField("Sites", "Sites", WithSortable(), WithCountScope(func(db *gorm.DB) *gorm.DB {
+			return db.Where("active = ?", true)
+		})),
+	}
+	res.Filters = []*Filter{
+		NewHasFilter("Has active sites", "sites", WithRelationScope(func(db *gorm.DB) *gorm.DB {
+			return db.Where("active = ?", true)
+		})),
+		NewHasFilter("Tagged", "Tags"),
+		NewFilter("Sites count", WithOperators(OpGte)),
+	}
+	return res, mock, sqlDB.Close
+}
+
+func TestHasFilter(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newSiteOwnerResource(t, "/users?filters[has_active_sites]=1&filters[tagged]=false&hidden=sites_count")
+	defer closeDB()
+
+	where := `WHERE EXISTS (SELECT 1 FROM "sites" WHERE "sites"."user_id" = "users"."id" AND active = $1) ` +
+		`AND NOT EXISTS (SELECT 1 FROM "tags" JOIN "user_tags" ON "user_tags"."tag_id" = "tags"."id" WHERE "user_tags"."user_id" = "users"."id")`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" ` + where)).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" `+where)).
+		WithArgs(true, 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var owners []SiteOwner
+	_, err := res.Paginate(res, owners)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestCountField(t *testing.T) {
+	is := assert.New(t)
+	res, mock, closeDB := newSiteOwnerResource(t, "/users?sort=-sites_count&filters[sites_count][gte]=2")
+	defer closeDB()
+
+	from := `FROM (SELECT "users".*, (SELECT COUNT(*) FROM "sites" WHERE "sites"."user_id" = "users"."id" AND active = $1) AS "sites_count" FROM "users") AS "users" WHERE sites_count >= $2`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) `+from)).
+		WithArgs(true, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * `+from+` ORDER BY sites_count DESC LIMIT $3`)).
+		WithArgs(true, 2, 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "sites_count"}).AddRow(1, 3))
+
+	var owners []SiteOwner
+	resp, err := res.Paginate(res, owners)
+	is.Nil(err)
+	is.Equal(3, resp["records"].([]SiteOwner)[0].SitesCount)
+	is.Equal("sites_count", resp["tableProps"].(TableProps).Columns[1].Attribute)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+type ArchivedSite struct {
+	ID        uint           `json:"id"`
+	UserID    uint           `json:"user_id"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+}
+
+func (ArchivedSite) TableName() string {
+	return "sites"
+}
+
+type ArchivedSiteOwner struct {
+	ID         uint           `json:"id"`
+	Sites      []ArchivedSite `gorm:"foreignKey:UserID" json:"sites"`
+	SitesCount int            `gorm:"->;-:migration" json:"sites_count"`
+}
+
+func (ArchivedSiteOwner) TableName() string {
+	return "users"
+}
+
+func TestRelationSoftDelete(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?filters[has_sites]=1", nil)
+	res := NewUserResource(db, request)
+	res.Fields = []*Field{NewField("ID"), NewCountField("Sites", "Sites")}
+	res.Filters = []*Filter{NewHasFilter("Has sites", "Sites")}
+
+	from := `FROM (SELECT "users".*, (SELECT COUNT(*) FROM "sites" WHERE "sites"."user_id" = "users"."id" AND "sites"."deleted_at" IS NULL) AS "sites_count" FROM "users") AS "users" ` +
+		`WHERE EXISTS (SELECT 1 FROM "sites" WHERE "sites"."user_id" = "users"."id" AND "sites"."deleted_at" IS NULL)`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) ` + from)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * ` + from + ` ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "sites_count"}).AddRow(1, 2))
+
+	var owners []ArchivedSiteOwner
+	_, err := res.Paginate(res, owners)
+	is.Nil(err)
+	is.Nil(mock.ExpectationsWereMet())
+}
@@ -0,0 +1,73 @@
+package tables
+
+import "context"
+
+// Page is a typed page of records
+type Page[T any] struct {
+	Records    []T          `json:"records"`
+	Pagination Pagination   `json:"pagination"`
+	TableProps TableProps   `json:"tableProps"`
+	RowActions []RowActions `json:"rowActions,omitempty"`
+}
+
+// Response returns the page as a Response map for inertia rendering
+func (p *Page[T]) Response() Response {
+	resp := Response{
+		"records":    p.Records,
+		"tableProps": p.TableProps,
+		"pagination": p.Pagination,
+	}
+	if p.RowActions != nil {
+		resp["rowActions"] = p.RowActions
+	}
+	return resp
+}
+
+// Resource paginates a table resource into typed pages of T
+type Resource[T any] struct {
+	Table TableResource
+}
+
+// NewResource creates a typed resource for the table
+func NewResource[T any](table TableResource) *Resource[T] {
+	return &Resource[T]{Table: table}
+}
+
+// Paginate returns a typed page using the table request's context
+func (r *Resource[T]) Paginate() (*Page[T], error) {
+	ctx := context.Background()
+	if req := r.Table.Base().Request; req != nil {
+		ctx = req.Context()
+	}
+	return Paginate[T](ctx, r.Table)
+}
+
+// Paginate is the typed equivalent of AbstractResource.PaginateContext,
+// the same errors are returned and the page is nil on error
+func Paginate[T any](ctx context.Context, table TableResource) (*Page[T], error) {
+	r := table.Base()
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	p, err := r.paginate(ctx, table, []T{})
+	if err != nil {
+		return nil, err
+	}
+
+	records, _ := p.Rows.([]T)
+	if records == nil {
+		records = []T{}
+	}
+
+	page := &Page[T]{
+		Records:    records,
+		Pagination: p.summary(),
+		TableProps: r.tableProps(p),
+	}
+	if r.hasRowActions() {
+		page.RowActions = r.resolveRowActions(records)
+	}
+	r.maskRows(records)
+	return page, nil
+}
@@ -0,0 +1,68 @@
+package tables
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestTypedPaginate(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?perPage=30&sort=-last_name", nil)
+	res := NewUserResource(db, request)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY last_name DESC LIMIT $1`)).
+		WithArgs(30).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name"}).AddRow(1, "foo", "bar"))
+
+	page, err := NewResource[UserPrivate](res).Paginate()
+	is.Nil(err)
+
+	is.Equal(uint(1), page.Records[0].ID)
+	is.Equal("foo", page.Records[0].FirstName)
+	is.Equal(30, page.Pagination.Limit)
+	is.Equal(int64(1), page.Pagination.TotalRows)
+	is.Nil(page.Pagination.Rows)
+	is.Equal([]string{"-last_name"}, page.TableProps.Sort)
+
+	resp := page.Response()
+	is.Equal(page.Records, resp["records"])
+	is.Equal(page.Pagination, resp["pagination"])
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestTypedPaginateEmptyAndErrors(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+	res.PaginationMode = SimplePagination
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(26).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	page, err := Paginate[UserPrivate](context.Background(), res)
+	is.Nil(err)
+	is.NotNil(page.Records)
+	is.Empty(page.Records)
+
+	request, _ = http.NewRequest(http.MethodGet, "/users?sort=password", nil)
+	res = NewUserResource(db, request)
+
+	page, err = Paginate[UserPrivate](context.Background(), res)
+	is.ErrorIs(err, ErrValidation)
+	is.Nil(page)
+	is.Nil(mock.ExpectationsWereMet())
+}
@@ -0,0 +1,149 @@
+package tables
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// trimRows cuts a result slice fetched with limit+1 rows down to the limit
+// and reports whether the extra row was found
+func trimRows(rows any, limit int) (reflect.Value, bool) {
+	v := reflect.ValueOf(rows)
+	if v.Len() > limit {
+		return v.Slice(0, limit), true
+	}
+	return v, false
+}
+
+// reverseSlice returns a copy of the slice in reverse order
+func reverseSlice(s reflect.Value) reflect.Value {
+	out := reflect.MakeSlice(s.Type(), s.Len(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		out.Index(i).Set(s.Index(s.Len() - 1 - i))
+	}
+	return out
+}
+
+// attributeValue reads an attribute from a map row or from the struct field
+// matching its json tag or column name, embedded structs are searched too
+func attributeValue(row any, attribute string) any {
+	if v := attributeField(reflect.ValueOf(row), attribute); v.IsValid() {
+		return v.Interface()
+	}
+	return nil
+}
+
+// attributeField finds the map value or struct field holding an attribute,
+// struct fields can be set when the row is addressable
+func attributeField(v reflect.Value, attribute string) reflect.Value {
+	v = reflect.Indirect(v)
+
+	switch v.Kind() {
+	case reflect.Map:
+		return v.MapIndex(reflect.ValueOf(attribute))
+	case reflect.Struct:
+		naming := schema.NamingStrategy{}
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			tag, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			if tag == attribute || (tag == "" && naming.ColumnName("", sf.Name) == attribute) {
+				return v.Field(i)
+			}
+			if sf.Anonymous && tag == "" {
+				if f := attributeField(v.Field(i), attribute); f.IsValid() {
+					return f
+				}
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// maskAttribute replaces an attribute of a map or addressable struct row with its masked value,
+// values that can't be assigned to the attribute's type leave the zero value. Dotted attributes
+// such as "client.title" are masked in the loaded relation, in every row of has many relations
+func maskAttribute(row reflect.Value, attribute string, mask func(any) any) {
+	row = reflect.Indirect(row)
+
+	field := attributeField(row, attribute)
+	if head, rest, nested := strings.Cut(attribute, "."); !field.IsValid() && nested {
+		related := attributeField(row, head)
+		for related.Kind() == reflect.Pointer || related.Kind() == reflect.Interface {
+			related = related.Elem()
+		}
+		switch related.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < related.Len(); i++ {
+				maskAttribute(related.Index(i), rest, mask)
+			}
+		case reflect.Struct, reflect.Map:
+			maskAttribute(related, rest, mask)
+		}
+		return
+	}
+	if !field.IsValid() {
+		return
+	}
+
+	masked := reflect.ValueOf(mask(field.Interface()))
+
+	if row.Kind() == reflect.Map {
+		if !masked.IsValid() || !masked.Type().AssignableTo(row.Type().Elem()) {
+			masked = reflect.Zero(row.Type().Elem())
+		}
+		row.SetMapIndex(reflect.ValueOf(attribute), masked)
+		return
+	}
+
+	if !field.CanSet() {
+		return
+	}
+	if masked.IsValid() && masked.Type().AssignableTo(field.Type()) {
+		field.Set(masked)
+	} else {
+		field.SetZero()
+	}
+}
+
+// clearValue is the mask of denied fields, their values are reset to the zero value
+func clearValue(any) any {
+	return nil
+}
+
+// pathValue reads a dot separated attribute path such as "client.id" from a row
+func pathValue(row any, path string) any {
+	for _, attribute := range strings.Split(path, ".") {
+		if row = attributeValue(row, attribute); row == nil {
+			return nil
+		}
+	}
+	return row
+}
+
+// plainValue unwraps pointers and driver values such as sql.NullString, NULL values become nil
+func plainValue(v any) any {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+
+	v = rv.Interface()
+	if valuer, ok := v.(driver.Valuer); ok {
+		if dv, err := valuer.Value(); err == nil {
+			return dv
+		}
+	}
+	return v
+}
@@ -0,0 +1,85 @@
+package tables
+
+import (
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"reflect"
+	"testing"
+)
+
+func TestAttributeValue(t *testing.T) {
+	is := assert.New(t)
+
+	user := UserPrivate{ID: 3, FirstName: "foo", ClientId: 9}
+
+	is.Equal("foo", attributeValue(user, "first_name"))
+	is.Equal(uint(3), attributeValue(&user, "id"))
+	is.Equal(9, attributeValue(user, "client_id"))
+	is.Nil(attributeValue(user, "password"))
+	is.Equal("bar", attributeValue(map[string]any{"last_name": "bar"}, "last_name"))
+}
+
+func TestPathValue(t *testing.T) {
+	is := assert.New(t)
+
+	type Account struct {
+		gorm.Model
+		Owner *UserPrivate `json:"owner"`
+	}
+
+	account := Account{Model: gorm.Model{ID: 4}, Owner: &UserPrivate{Client: Client{ID: 9, Title: "acme"}}}
+
+	is.Equal(uint(4), pathValue(account, "id"))
+	is.Equal(uint(9), pathValue(account, "owner.client.id"))
+	is.Equal("acme", pathValue(account, "owner.client.title"))
+	is.Nil(pathValue(account, "owner.site.id"))
+	is.Nil(pathValue(Account{}, "owner.client.id"))
+	is.Equal(2, pathValue(map[string]any{"client": map[string]any{"id": 2}}, "client.id"))
+}
+
+func TestMaskAttribute(t *testing.T) {
+	is := assert.New(t)
+
+	users := []UserPrivate{{ID: 3, Email: "foo@example.com"}}
+	maskAttribute(reflect.ValueOf(users).Index(0), "email", func(v any) any {
+		return "f***@example.com"
+	})
+	maskAttribute(reflect.ValueOf(users).Index(0), "id", func(v any) any {
+		return "hidden"
+	})
+	is.Equal("f***@example.com", users[0].Email)
+	is.Equal(uint(0), users[0].ID)
+
+	row := map[string]any{"salary": 1000}
+	maskAttribute(reflect.ValueOf(row), "salary", func(v any) any {
+		return nil
+	})
+	maskAttribute(reflect.ValueOf(row), "bonus", func(v any) any {
+		return 1
+	})
+	is.Equal(map[string]any{"salary": nil}, row)
+
+	// Dotted attributes are masked in loaded relations
+	users[0].Client = Client{ID: 9, Title: "acme"}
+	maskAttribute(reflect.ValueOf(users).Index(0), "client.title", clearValue)
+	is.Equal(Client{ID: 9}, users[0].Client)
+
+	rows := []map[string]any{{"sites": []map[string]any{{"name": "a"}, {"name": "b"}}}}
+	maskAttribute(reflect.ValueOf(rows).Index(0), "sites.name", clearValue)
+	is.Equal([]map[string]any{{"name": nil}, {"name": nil}}, rows[0]["sites"])
+}
+
+func TestPlainValue(t *testing.T) {
+	is := assert.New(t)
+
+	name := "foo"
+	var missing *string
+
+	is.Equal("foo", plainValue(&name))
+	is.Nil(plainValue(missing))
+	is.Nil(plainValue(nil))
+	is.Nil(plainValue(sql.NullString{}))
+	is.Equal("bar", plainValue(sql.NullString{String: "bar", Valid: true}))
+	is.Equal(3, plainValue(3))
+}
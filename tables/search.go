@@ -2,10 +2,28 @@ package tables
 
 import (
 	"strconv"
+	"strings"
+	"unicode"
 
 	"gorm.io/gorm"
 )
 
+// SearchMode selects how search values are matched
+type SearchMode int
+
+const (
+	// SubstringSearch matches the whole search value as a single substring
+	SubstringSearch SearchMode = iota
+	// TokenizedSearch matches every term of the search value, see ParseSearchTerms
+	TokenizedSearch
+)
+
+// SearchTerm is a single term of a tokenized search value
+type SearchTerm struct {
+	Text    string
+	Exclude bool
+}
+
 type Search struct {
 	Label   string `json:"label"`
 	Field   string `json:"field"`
@@ -17,6 +35,36 @@ func (f *Search) ApplySearch(db *gorm.DB) {
 	if v, err := strconv.Atoi(f.Value); err == nil {
 		db.Where(f.Field+" = ?", v)
 	} else {
-		db.Where(f.Field+" ILIKE ?", "%"+f.Value+"%")
+		db.Where(DialectOf(db).Contains(f.Field), "%"+f.Value+"%")
+	}
+}
+
+// ParseSearchTerms splits a search value into whitespace separated terms, double quoted
+// phrases are kept together and a leading "-" excludes the term
+func ParseSearchTerms(value string) []SearchTerm {
+	var terms []SearchTerm
+
+	for value = strings.TrimSpace(value); value != ""; value = strings.TrimLeftFunc(value, unicode.IsSpace) {
+		var t SearchTerm
+		if value[0] == '-' {
+			t.Exclude = true
+			value = value[1:]
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			// An unterminated phrase runs to the end of the value
+			t.Text, value, _ = strings.Cut(value[1:], `"`)
+		} else {
+			end := strings.IndexFunc(value, unicode.IsSpace)
+			if end < 0 {
+				end = len(value)
+			}
+			t.Text, value = value[:end], value[end:]
+		}
+
+		if t.Text = strings.TrimSpace(t.Text); t.Text != "" {
+			terms = append(terms, t)
+		}
 	}
+	return terms
 }
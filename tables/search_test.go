@@ -52,6 +52,20 @@ func (suite *SearchTestSuite) TestDefaultRequest() {
 	suite.Nil(mock.ExpectationsWereMet())
 }
 
+func (suite *SearchTestSuite) TestParseSearchTerms() {
+	tests := map[string][]SearchTerm{
+		"john smith":            {{Text: "john"}, {Text: "smith"}},
+		`  "van dyke"  -smith `: {{Text: "van dyke"}, {Text: "smith", Exclude: true}},
+		`-"john smith" o"neil`:  {{Text: "john smith", Exclude: true}, {Text: `o"neil`}},
+		`"unterminated phrase`:  {{Text: "unterminated phrase"}},
+		` - "" -"  " `:          nil,
+	}
+
+	for value, want := range tests {
+		suite.Equal(want, ParseSearchTerms(value), value)
+	}
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestSearchTestSuite(t *testing.T) {
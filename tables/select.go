@@ -0,0 +1,101 @@
+package tables
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// selectColumns returns the columns selected when SelectVisible is set: the primary key,
+// the visible fields, the sort columns and the keys needed by Preloads and action params.
+// Columns missing from the model's schema are skipped, models without a schema such as
+// maps select the attributes as they are
+func (r *AbstractResource) selectColumns(q *gorm.DB, sort string) []string {
+	r.FlagVisibility()
+
+	var (
+		sch     *schema.Schema
+		columns []string
+		seen    = make(map[string]bool)
+	)
+	if err := q.Statement.Parse(q.Statement.Model); err == nil {
+		sch = q.Statement.Schema
+	}
+
+	column := func(name string) {
+		if sch != nil {
+			f := sch.LookUpField(name)
+			if f == nil || f.DBName == "" {
+				return
+			}
+			name = f.DBName
+		}
+		if !seen[name] {
+			seen[name] = true
+			columns = append(columns, name)
+		}
+	}
+
+	// attribute selects a column, or the keys of the relationship an attribute path starts with
+	attribute := func(name string) {
+		head, _, nested := strings.Cut(name, ".")
+		if rel := relation(sch, head); rel != nil {
+			for _, ref := range rel.References {
+				if ref.PrimaryKey != nil && ref.PrimaryKey.Schema == sch {
+					column(ref.PrimaryKey.DBName)
+				}
+				if ref.ForeignKey != nil && ref.ForeignKey.Schema == sch {
+					column(ref.ForeignKey.DBName)
+				}
+			}
+			return
+		}
+		if !nested {
+			column(name)
+		}
+	}
+
+	column(r.primaryKey())
+
+	for _, f := range r.Fields {
+		switch {
+		case f.Actions != nil:
+			for _, a := range f.Actions {
+				if a.allowed(r.Request) {
+					for _, param := range a.params() {
+						attribute(param)
+					}
+				}
+			}
+		case f.Visible && !f.denied(r.Request):
+			attribute(f.Attribute)
+		}
+	}
+
+	for _, c := range parseSort(sort) {
+		attribute(c.Column)
+	}
+
+	for _, p := range r.Preloads {
+		attribute(p.Name)
+	}
+
+	return columns
+}
+
+// relation finds a relationship by field name, json tag or column style name
+func relation(sch *schema.Schema, name string) *schema.Relationship {
+	if sch == nil {
+		return nil
+	}
+
+	naming := schema.NamingStrategy{}
+	for _, rel := range sch.Relationships.Relations {
+		tag, _, _ := strings.Cut(rel.Field.Tag.Get("json"), ",")
+		if rel.Name == name || tag == name || naming.ColumnName("", rel.Name) == name {
+			return rel
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package tables
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/humweb/go-tables/testutils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestSelectVisible(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, mock := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?hidden=first_name,email&sort=-first_name", nil)
+	res := NewUserResource(db, request)
+	res.SelectVisible = true
+	res.Preloads = []Preload{{Name: "Client"}}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","last_name","username","first_name","client_id" FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client_id"}).AddRow(1, 2))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "clients" WHERE "clients"."id" = $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(2, "acme"))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	is.Nil(err)
+	is.Equal("acme", resp["records"].([]UserPrivate)[0].Client.Title)
+	is.Nil(mock.ExpectationsWereMet())
+}
+
+func TestSelectColumnsWithoutSchema(t *testing.T) {
+	is := assert.New(t)
+	sqlDB, db, _ := testutils.DBMock(t)
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?hidden=email", nil)
+	res := NewUserResource(db, request)
+	res.Fields[5] = NewActionField("Actions", []*ActionItems{{Label: "Client", Link: "/clients/{client.id}/{client_id}"}})
+
+	q := db.Table("users").Model(&[]map[string]any{})
+	is.Equal([]string{"id", "first_name", "last_name", "username", "client_id"}, res.selectColumns(q, "id ASC"))
+}
@@ -55,7 +55,7 @@ func NewUserResource(db *gorm.DB, req *http.Request) *UserResource {
 
 func (u *UserResource) GetFields() []*Field {
 	return []*Field{
-		NewField("ID", WithSortable()),
+		NewField("ID", WithSortable(), WithSearchable()),
 		NewField("First name", WithSortable(), WithVisibility(), WithArraySort()),
 		NewField("Last name", WithSortable(), WithSearchable()),
 		NewField("Email", WithSortable()),
@@ -78,7 +78,7 @@ func (u *UserResource) GetFields() []*Field {
 
 func (u *UserResource) GetFilters() []*Filter {
 	return []*Filter{
-		NewFilter("ID"),
+		NewFilter("ID", WithOperators(OpGte, OpLte, OpIn, OpBetween)),
 		NewFilter("Client ID"),
 	}
 }
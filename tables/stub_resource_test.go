@@ -2,6 +2,9 @@ package tables
 
 // Basic imports
 import (
+	"context"
+	"database/sql/driver"
+	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/humweb/go-tables/testutils"
 	"github.com/stretchr/testify/suite"
@@ -9,6 +12,7 @@ import (
 	"net/http"
 	"regexp"
 	"testing"
+	"time"
 )
 
 type ResourceTestSuite struct {
@@ -382,48 +386,689 @@ func (suite *ResourceTestSuite) TestDefaultPerPage() {
 	suite.Nil(mock.ExpectationsWereMet())
 }
 
-//
-//func (suite *ResourceTestSuite) TestArraySort() {
-//	sqlDB, db, mock := testutils.DBMock(suite.T())
-//	defer sqlDB.Close()
-//	request, _ := http.NewRequest(http.MethodGet, "/users?filters[id]=1&sort=first_name", nil)
-//	res := NewUserResource(db, request)
-//
-//	res.DefaultPerPage = 100
-//
-//	users := sqlmock.
-//		NewRows([]string{"id", "first_name", "last_name", "username", "password"}).
-//		AddRow(1, "foo", "bar", "baz", "passwd").
-//		AddRow(1, "afoo", "abar", "abaz", "passwd")
-//
-//	expectedCountSQL := regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE id = $1`)
-//	mock.ExpectQuery(expectedCountSQL).
-//		WithArgs(1).
-//		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
-//
-//	expectedSQL := regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1 ORDER BY id DESC LIMIT $2`)
-//	mock.ExpectQuery(expectedSQL).
-//		WithArgs(1, 100).
-//		WillReturnRows(users)
-//
-//	var aryUsers []UserPrivate
-//	resp, _ := res.Paginate(res, aryUsers)
-//
-//	records := resp["records"].([]map[string]any)
-//
-//	suite.Equal(uint(1), records[0]["id"])
-//	suite.Equal("afoo", records[0]["first_name"])
-//	suite.Equal("abar", records[0]["last_name"])
-//	suite.Equal("abaz", records[0]["username"])
-//
-//	pagination := resp["pagination"].(Pagination)
-//
-//	suite.Equal(100, pagination.Limit)
-//	suite.Equal(1, pagination.Page)
-//	suite.Equal(1, pagination.TotalPages)
-//	suite.Equal(int64(1), pagination.TotalRows)
-//	suite.Nil(mock.ExpectationsWereMet())
-//}
+func (suite *ResourceTestSuite) TestArraySort() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=-first_name&perPage=2&page=1", nil)
+	res := NewUserResource(db, request)
+
+	users := sqlmock.
+		NewRows([]string{"id", "first_name", "last_name", "username", "password"}).
+		AddRow(1, "bfoo", "bar", "baz", "passwd").
+		AddRow(2, "afoo", "abar", "abaz", "passwd").
+		AddRow(3, "cfoo", "cbar", "cbaz", "passwd")
+
+	expectedCountSQL := regexp.QuoteMeta(`SELECT count(*) FROM "users"`)
+	mock.ExpectQuery(expectedCountSQL).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	expectedSQL := regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)
+	mock.ExpectQuery(expectedSQL).
+		WithArgs(DefaultArraySortLimit + 1).
+		WillReturnRows(users)
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	suite.Nil(err)
+
+	records := resp["records"].([]UserPrivate)
+
+	suite.Len(records, 2)
+	suite.Equal(uint(3), records[0].ID)
+	suite.Equal("cfoo", records[0].FirstName)
+	suite.Equal("bfoo", records[1].FirstName)
+
+	pagination := resp["pagination"].(Pagination)
+
+	suite.Equal(2, pagination.Limit)
+	suite.Equal(1, pagination.Page)
+	suite.Equal(2, pagination.TotalPages)
+	suite.Equal(int64(3), pagination.TotalRows)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestArraySortLimit() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=first_name", nil)
+	res := NewUserResource(db, request)
+	res.ArraySortLimit = 2
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+
+	suite.ErrorIs(err, ErrArraySortLimit)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestFilterOperators() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?filters[id][gte]=10&filters[id][lte]=20", nil)
+	res := NewUserResource(db, request)
+
+	expectedCountSQL := regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE id >= $1 AND id <= $2`)
+	mock.ExpectQuery(expectedCountSQL).
+		WithArgs(10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	expectedSQL := regexp.QuoteMeta(`SELECT * FROM "users" WHERE id >= $1 AND id <= $2 ORDER BY id ASC LIMIT $3`)
+	mock.ExpectQuery(expectedSQL).
+		WithArgs(10, 20, 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+
+	suite.Nil(err)
+	suite.Equal("10", res.Filters[0].Values["gte"])
+	suite.Equal("20", res.Filters[0].Values["lte"])
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestFilterOperatorNotAllowed() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?filters[id][neq]=10", nil)
+	res := NewUserResource(db, request)
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+
+	var verr *ValidationError
+	suite.ErrorAs(err, &verr)
+	suite.Equal("id[neq]", verr.Key)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestCustomFilterQuery() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?filters[client]=acme", nil)
+	res := NewUserResource(db, request)
+
+	res.Filters = append(res.Filters, NewFilter("Client", WithQuery(func(db *gorm.DB, value string) error {
+		db.Where("client_id IN (SELECT id FROM clients WHERE title = ?)", value)
+		return nil
+	})))
+
+	expectedCountSQL := regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE client_id IN (SELECT id FROM clients WHERE title = $1)`)
+	mock.ExpectQuery(expectedCountSQL).
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	expectedSQL := regexp.QuoteMeta(`SELECT * FROM "users" WHERE client_id IN (SELECT id FROM clients WHERE title = $1) ORDER BY id ASC LIMIT $2`)
+	mock.ExpectQuery(expectedSQL).
+		WithArgs("acme", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+
+	suite.Nil(err)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestCursorPagination() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+
+	// First page
+	request, _ := http.NewRequest(http.MethodGet, "/users?perPage=2&sort=last_name", nil)
+	res := NewUserResource(db, request)
+	res.PaginationMode = CursorPagination
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY last_name ASC,id ASC LIMIT $1`)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}).
+			AddRow(1, "a").
+			AddRow(2, "b").
+			AddRow(3, "c"))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	suite.Nil(err)
+
+	records := resp["records"].([]UserPrivate)
+	pagination := resp["pagination"].(Pagination)
+	suite.Len(records, 2)
+	suite.Empty(pagination.PrevCursor)
+	suite.NotEmpty(pagination.NextCursor)
+
+	next, _ := DecodeCursor(pagination.NextCursor)
+	suite.Equal([]any{"b", int64(2)}, next.Values)
+
+	// Second page
+	request, _ = http.NewRequest(http.MethodGet, "/users?perPage=2&sort=last_name&cursor="+pagination.NextCursor, nil)
+	res = NewUserResource(db, request)
+	res.PaginationMode = CursorPagination
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE ((last_name > $1 OR last_name IS NULL) OR (last_name = $2 AND id > $3)) ORDER BY last_name ASC,id ASC LIMIT $4`)).
+		WithArgs("b", "b", 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}).
+			AddRow(3, "c"))
+
+	resp, err = res.Paginate(res, aryUsers)
+	suite.Nil(err)
+
+	records = resp["records"].([]UserPrivate)
+	pagination = resp["pagination"].(Pagination)
+	suite.Len(records, 1)
+	suite.Empty(pagination.NextCursor)
+	suite.NotEmpty(pagination.PrevCursor)
+
+	// Back to the first page
+	request, _ = http.NewRequest(http.MethodGet, "/users?perPage=2&sort=last_name&cursor="+pagination.PrevCursor, nil)
+	res = NewUserResource(db, request)
+	res.PaginationMode = CursorPagination
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE (last_name < $1 OR (last_name = $2 AND id < $3)) ORDER BY last_name DESC,id DESC LIMIT $4`)).
+		WithArgs("c", "c", 3, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}).
+			AddRow(2, "b").
+			AddRow(1, "a"))
+
+	resp, err = res.Paginate(res, aryUsers)
+	suite.Nil(err)
+
+	records = resp["records"].([]UserPrivate)
+	pagination = resp["pagination"].(Pagination)
+	suite.Equal("a", records[0].LastName)
+	suite.Equal("b", records[1].LastName)
+	suite.Empty(pagination.PrevCursor)
+	suite.NotEmpty(pagination.NextCursor)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestCursorAfterNull() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+
+	// NULLs sort last in ascending order, only NULL rows with a greater id follow
+	cursor := Cursor{Sort: "last_name ASC", Values: []any{nil, 2}}.Encode()
+	request, _ := http.NewRequest(http.MethodGet, "/users?perPage=2&sort=last_name&cursor="+cursor, nil)
+	res := NewUserResource(db, request)
+	res.PaginationMode = CursorPagination
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE (last_name IS NULL AND id > $1) ORDER BY last_name ASC,id ASC LIMIT $2`)).
+		WithArgs(2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}).
+			AddRow(3, nil))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	suite.Nil(err)
+	suite.Len(resp["records"].([]UserPrivate), 1)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestCursorNullsOrder() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+
+	cursor := Cursor{Sort: "last_name ASC", Values: []any{"b", 2}}.Encode()
+	request, _ := http.NewRequest(http.MethodGet, "/users?perPage=2&sort=last_name&cursor="+cursor, nil)
+	res := NewUserResource(db, request)
+	res.PaginationMode = CursorPagination
+	res.Fields[2] = NewField("Last name", WithSortable(), WithNullsFirst())
+
+	// NULLs come first, so none follow a value
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE (last_name > $1 OR (last_name = $2 AND id > $3)) ORDER BY last_name ASC NULLS FIRST,id ASC LIMIT $4`)).
+		WithArgs("b", "b", 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	suite.Nil(err)
+
+	// Previous pages reverse the order, NULLs then come last
+	cursor = Cursor{Sort: "last_name ASC", Values: []any{"b", 2}, Prev: true}.Encode()
+	res.Request, _ = http.NewRequest(http.MethodGet, "/users?perPage=2&sort=last_name&cursor="+cursor, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE ((last_name < $1 OR last_name IS NULL) OR (last_name = $2 AND id < $3)) ORDER BY last_name DESC NULLS LAST,id DESC LIMIT $4`)).
+		WithArgs("b", "b", 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "last_name"}))
+
+	_, err = res.Paginate(res, aryUsers)
+	suite.Nil(err)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestCursorTimeColumn() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, "/users?perPage=1&sort=created_at", nil)
+	res := NewUserResource(db, request)
+	res.PaginationMode = CursorPagination
+	res.Fields = append(res.Fields, NewField("Created at", WithSortable()))
+
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY created_at ASC,id ASC LIMIT $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).
+			AddRow(1, at).
+			AddRow(2, at.Add(time.Hour)))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	suite.Nil(err)
+
+	// The next page compares against a time value, not its string form
+	next := resp["pagination"].(Pagination).NextCursor
+	res.Request, _ = http.NewRequest(http.MethodGet, "/users?perPage=1&sort=created_at&cursor="+next, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE ((created_at > $1 OR created_at IS NULL) OR (created_at = $2 AND id > $3)) ORDER BY created_at ASC,id ASC LIMIT $4`)).
+		WithArgs(at, at, 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+
+	_, err = res.Paginate(res, aryUsers)
+	suite.Nil(err)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestCursorSortMismatch() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+
+	cursor := Cursor{Sort: "id ASC", Values: []any{1}}.Encode()
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=last_name&cursor="+cursor, nil)
+	res := NewUserResource(db, request)
+	res.PaginationMode = CursorPagination
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+
+	var verr *ValidationError
+	suite.ErrorAs(err, &verr)
+	suite.Equal("cursor", verr.Param)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestSimplePagination() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?perPage=2&page=2", nil)
+	res := NewUserResource(db, request)
+	res.PaginationMode = SimplePagination
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1 OFFSET $2`)).
+		WithArgs(3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(4).AddRow(5))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	suite.Nil(err)
+
+	records := resp["records"].([]UserPrivate)
+	pagination := resp["pagination"].(Pagination)
+
+	suite.Len(records, 2)
+	suite.True(pagination.HasMore)
+	suite.Equal(2, pagination.Page)
+	suite.Equal(SimplePagination, pagination.Mode)
+	suite.IsType(TableProps{}, resp["tableProps"])
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestMultiColumnSort() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?sort=-last_name,email", nil)
+	res := NewUserResource(db, request)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY last_name DESC, email ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+
+	suite.Nil(err)
+	suite.Equal([]string{"-last_name", "email"}, resp["tableProps"].(TableProps).Sort)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestInvalidKeys() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+
+	tests := map[string]struct {
+		url   string
+		param string
+		key   string
+	}{
+		"unsortable field":  {"/users?sort=-password", "sort", "password"},
+		"unsortable second": {"/users?sort=id,-password", "sort", "password"},
+		"sql fragment":      {"/users?sort=id%3Bdrop%20table%20users", "sort", "id;drop table users"},
+		"unsearchable":      {"/users?search[email]=foo", "search", "email"},
+		"unknown filter":    {"/users?filters[password]=foo", "filter", "password"},
+	}
+
+	for name, tt := range tests {
+		request, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		res := NewUserResource(db, request)
+
+		var aryUsers []UserPrivate
+		resp, err := res.Paginate(res, aryUsers)
+
+		var verr *ValidationError
+		suite.True(errors.As(err, &verr), name)
+		suite.ErrorIs(err, ErrValidation, name)
+		suite.Equal(tt.param, verr.Param, name)
+		suite.Equal(tt.key, verr.Key, name)
+		suite.Nil(resp, name)
+	}
+
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestGlobalSearchDisabled() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users?search[global]=foo", nil)
+	res := NewUserResource(db, request)
+	res.HasGlobalSearch = false
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+
+	var verr *ValidationError
+	suite.True(errors.As(err, &verr))
+	suite.Equal("global", verr.Key)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestCountError() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnError(errors.New("connection refused"))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+
+	suite.ErrorIs(err, ErrCount)
+	suite.ErrorIs(err, ErrQuery)
+	suite.Nil(resp)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestSelectError() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnError(errors.New("connection refused"))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+
+	suite.ErrorIs(err, ErrQuery)
+	suite.NotErrorIs(err, ErrCount)
+	suite.Nil(resp)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestRequestContextCanceled() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+
+	suite.ErrorIs(err, ErrCount)
+	suite.ErrorIs(err, context.Canceled)
+	suite.Nil(resp)
+}
+
+func (suite *ResourceTestSuite) TestQueryTimeout() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+	res.QueryTimeout = 10 * time.Millisecond
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	var aryUsers []UserPrivate
+	_, err := res.PaginateContext(context.Background(), res, aryUsers)
+
+	suite.ErrorIs(err, ErrQuery)
+	suite.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (suite *ResourceTestSuite) TestToResponseWithoutRequest() {
+	res := NewUserResource(nil, nil)
+
+	resp := res.ToResponse(nil)
+
+	suite.Equal([]string{"id"}, resp["tableProps"].(TableProps).Sort)
+	suite.Contains(resp["tableProps"].(TableProps).Search, "global")
+}
+
+func (suite *ResourceTestSuite) TestPaginateWithoutRequest() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	res := NewUserResource(db, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+
+	suite.Nil(err)
+	suite.Len(resp["records"].([]UserPrivate), 1)
+	suite.Nil(mock.ExpectationsWereMet())
+
+	// Cursor pagination reads the cursor from the missing query too
+	res = NewUserResource(db, nil)
+	res.PaginationMode = CursorPagination
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(26).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	_, err = res.Paginate(res, aryUsers)
+	suite.Nil(err)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestFieldAuthorization() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	res := NewUserResource(db, request)
+
+	deny := func(r *http.Request) bool { return false }
+	res.Fields[3] = NewField("Email", WithSortable(), WithAuthorize(deny))
+	res.Fields[4] = NewField("Username", WithSortable(), WithSearchable(), WithAuthorize(deny), WithMask(func(v any) any {
+		return "***"
+	}))
+	res.Filters = append(res.Filters, NewFilter("Email"), NewFilter("Username", WithOperators(OpGte)))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "users"."id","users"."client_id","users"."first_name","users"."last_name","users"."username","users"."created_at","users"."updated_at" FROM "users" ORDER BY id ASC LIMIT $1`)).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "foo"))
+
+	var aryUsers []UserPrivate
+	resp, err := res.Paginate(res, aryUsers)
+	suite.Nil(err)
+
+	records := resp["records"].([]UserPrivate)
+	suite.Equal("***", records[0].Username)
+
+	props := resp["tableProps"].(TableProps)
+	suite.Len(props.Columns, len(res.Fields)-1)
+	suite.Equal("username", props.Columns[3].Attribute)
+	suite.False(props.Columns[3].Sortable)
+	suite.False(props.Columns[3].Searchable)
+	suite.NotContains(props.Search, "username")
+	suite.Len(props.Filters, len(res.Filters)-2)
+	suite.True(res.Fields[4].Sortable)
+	suite.Nil(mock.ExpectationsWereMet())
+
+	for _, url := range []string{
+		"/users?sort=email",
+		"/users?sort=-username",
+		"/users?search[username]=foo",
+		"/users?filters[email]=a@example.com",
+		"/users?filters[username][gte]=m",
+	} {
+		request, _ = http.NewRequest(http.MethodGet, url, nil)
+		res.Request = request
+
+		_, err = res.Paginate(res, aryUsers)
+		suite.ErrorIs(err, ErrValidation, url)
+	}
+}
+
+// defaultSearchResource relies on the default global search
+type defaultSearchResource struct {
+	AbstractResource
+}
+
+func (d *defaultSearchResource) GetFields() []*Field   { return d.Fields }
+func (d *defaultSearchResource) GetFilters() []*Filter { return d.Filters }
+func (d *defaultSearchResource) ApplyFilter(*gorm.DB)  {}
+
+func (suite *ResourceTestSuite) TestDefaultGlobalSearch() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+
+	tests := map[string]struct {
+		search string
+		where  string
+		args   []driver.Value
+	}{
+		"text":    {"foo", `(last_name ILIKE $1 OR email ILIKE $2)`, []driver.Value{"%foo%", "%foo%"}},
+		"integer": {"7", `(id = $1 OR last_name ILIKE $2 OR email ILIKE $3)`, []driver.Value{7, "%7%", "%7%"}},
+	}
+
+	for name, tt := range tests {
+		request, _ := http.NewRequest(http.MethodGet, "/users?search[global]="+tt.search, nil)
+		res := &defaultSearchResource{AbstractResource{DB: db, Request: request, HasGlobalSearch: true}}
+		res.Fields = []*Field{
+			NewField("ID", WithSearchable()),
+			NewField("Last name", WithSearchable()),
+			NewField("Email", WithSearchable()),
+			NewField("Created at", WithSearchable()),
+			NewField("Username"),
+			NewField("Full name", WithSearchable()),
+		}
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE ` + tt.where)).
+			WithArgs(tt.args...).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE ` + tt.where + ` ORDER BY id ASC`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		var aryUsers []UserPrivate
+		_, err := res.Paginate(res, aryUsers)
+		suite.Nil(err, name)
+	}
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestDefaultGlobalSearchCountField() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, "/users?search[global]=7&hidden=sites_count", nil)
+	res := &defaultSearchResource{AbstractResource{DB: db, Request: request, HasGlobalSearch: true}}
+	res.Fields = []*Field{
+		NewField("ID", WithSearchable()),
+		NewCountField("Sites", "Sites", WithSearchable()),
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE (id = $1)`)).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE (id = $1) ORDER BY id ASC`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var owners []SiteOwner
+	_, err := res.Paginate(res, owners)
+	suite.Nil(err)
+	suite.Nil(mock.ExpectationsWereMet())
+}
+
+func (suite *ResourceTestSuite) TestTokenizedSearch() {
+	sqlDB, db, mock := testutils.DBMock(suite.T())
+	defer sqlDB.Close()
+	request, _ := http.NewRequest(http.MethodGet, `/users?search[global]=john%20"van%20dyke"%20-smith`, nil)
+	res := &defaultSearchResource{AbstractResource{DB: db, Request: request, HasGlobalSearch: true, SearchMode: TokenizedSearch}}
+	res.Fields = []*Field{
+		NewField("Last name", WithSearchable()),
+		NewField("Email", WithSearchable()),
+	}
+
+	where := `WHERE ((last_name ILIKE $1 OR email ILIKE $2)) AND ((last_name ILIKE $3 OR email ILIKE $4)) ` +
+		`AND CASE WHEN ((last_name ILIKE $5 OR email ILIKE $6)) THEN 1 ELSE 0 END = 0`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" `+where)).
+		WithArgs("%john%", "%john%", "%van dyke%", "%van dyke%", "%smith%", "%smith%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ` + where + ` ORDER BY id ASC`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	var aryUsers []UserPrivate
+	_, err := res.Paginate(res, aryUsers)
+	suite.Nil(err)
+
+	request, _ = http.NewRequest(http.MethodGet, `/users?search[last_name]=foo%20-bar`, nil)
+	res.Request = request
+
+	where = `WHERE last_name ILIKE $1 AND CASE WHEN (last_name ILIKE $2) THEN 1 ELSE 0 END = 0`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" `+where)).
+		WithArgs("%foo%", "%bar%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" ` + where + ` ORDER BY id ASC`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = res.Paginate(res, aryUsers)
+	suite.Nil(err)
+	suite.Nil(mock.ExpectationsWereMet())
+}
 
 func (suite *ResourceTestSuite) TestFlagVisibility() {
 	sqlDB, db, _ := testutils.DBMock(suite.T())
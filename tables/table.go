@@ -10,3 +10,9 @@ type ITable interface {
 	WithGlobalSearch(db *gorm.DB, val string)
 	ApplyFilter(db *gorm.DB)
 }
+
+// TableResource is implemented by ITable resources embedding AbstractResource
+type TableResource interface {
+	ITable
+	Base() *AbstractResource
+}
@@ -1,6 +1,7 @@
 package tables
 
 import (
+	"encoding/json"
 	"gorm.io/gorm"
 	"net/url"
 	"strconv"
@@ -9,13 +10,62 @@ import (
 	"github.com/humweb/go-tables/utils"
 )
 
+// PaginationMode selects how a resource pages through its records
+type PaginationMode int
+
+const (
+	// LengthAwarePagination counts matching rows and pages with OFFSET/LIMIT
+	LengthAwarePagination PaginationMode = iota
+	// CursorPagination pages using keyset conditions on the sort columns and primary key
+	CursorPagination
+	// SimplePagination pages with OFFSET/LIMIT but only reports whether more rows exist
+	SimplePagination
+)
+
 type Pagination struct {
 	Limit      int    `json:"limit,omitempty"`
 	Page       int    `json:"page,omitempty"`
 	Sort       string `json:"sort,omitempty"`
 	TotalRows  int64  `json:"record_count"`
 	TotalPages int    `json:"total_pages"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 	Rows       any    `json:"rows"`
+
+	Mode PaginationMode `json:"-"`
+}
+
+// summary returns a copy of the pagination details without the rows
+func (p *Pagination) summary() Pagination {
+	return Pagination{
+		Limit:      p.Limit,
+		Page:       p.Page,
+		TotalPages: p.TotalPages,
+		TotalRows:  p.TotalRows,
+		HasMore:    p.HasMore,
+		NextCursor: p.NextCursor,
+		PrevCursor: p.PrevCursor,
+		Mode:       p.Mode,
+	}
+}
+
+// MarshalJSON omits the totals when they are not counted, and has_more when they are
+func (p Pagination) MarshalJSON() ([]byte, error) {
+	type pagination Pagination
+
+	if p.Mode == LengthAwarePagination {
+		return json.Marshal(struct {
+			pagination
+			HasMore *bool `json:"has_more,omitempty"`
+		}{pagination: pagination(p)})
+	}
+
+	return json.Marshal(struct {
+		pagination
+		TotalRows  *int64 `json:"record_count,omitempty"`
+		TotalPages *int   `json:"total_pages,omitempty"`
+	}{pagination: pagination(p)})
 }
 
 func (p *Pagination) GetOffset() int {
@@ -44,12 +94,13 @@ func (p *Pagination) GetSort() string {
 }
 
 type TableRequest struct {
-	Page         int               `json:"page"`
-	PerPage      int               `json:"perPage"`
-	Sort         string            `json:"sort"`
-	Search       map[string]string `json:"search"`
-	Filters      map[string]string `json:"filters"`
-	GlobalFilter Filter            `json:"global_filter"`
+	Page         int                            `json:"page"`
+	PerPage      int                            `json:"perPage"`
+	Sort         string                         `json:"sort"`
+	Search       map[string]string              `json:"search"`
+	Filters      map[string]string              `json:"filters"`
+	FilterOps    map[string]map[Operator]string `json:"filter_ops"`
+	GlobalFilter Filter                         `json:"global_filter"`
 }
 
 func (r *TableRequest) Fill(req *url.URL) {
@@ -67,20 +118,30 @@ func (r *TableRequest) Fill(req *url.URL) {
 }
 
 // SetFilterAndSearch Parses filter and search queries and builds key value maps
+// Filters using the nested syntax filters[field][op]=value are collected in FilterOps
 func (r *TableRequest) SetFilterAndSearch(query *url.URL) {
 	filters := make(map[string]string, strings.Count(query.RawQuery, "filters"))
+	filterOps := make(map[string]map[Operator]string)
 	search := make(map[string]string, strings.Count(query.RawQuery, "search"))
 
 	for key, val := range query.Query() {
 		if strings.HasPrefix(key, "filters") {
 			key = strings.TrimPrefix(strings.TrimSuffix(key, "]"), "filters[")
-			filters[key] = val[0]
+			if field, op, ok := strings.Cut(key, "]["); ok {
+				if filterOps[field] == nil {
+					filterOps[field] = make(map[Operator]string)
+				}
+				filterOps[field][Operator(op)] = val[0]
+			} else {
+				filters[key] = val[0]
+			}
 		} else if strings.HasPrefix(key, "search") {
 			key = strings.TrimPrefix(strings.TrimSuffix(key, "]"), "search[")
 			search[key] = val[0]
 		}
 	}
 	r.Filters = filters
+	r.FilterOps = filterOps
 	r.Search = search
 }
 
@@ -1,7 +1,9 @@
 package tables
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
+	"net/url"
 	"testing"
 )
 
@@ -15,3 +17,29 @@ func TestPaginationDefaults(t *testing.T) {
 	is.Equal(1, p.GetPage())
 	is.Equal("id DESC", p.GetSort())
 }
+
+func TestFilterOperatorSyntax(t *testing.T) {
+	is := assert.New(t)
+	u, _ := url.Parse("/products?filters[status]=open&filters[price][gte]=10&filters[price][lte]=20")
+
+	r := &TableRequest{}
+	r.SetFilterAndSearch(u)
+
+	is.Equal("open", r.Filters["status"])
+	is.NotContains(r.Filters, "price")
+	is.Equal("10", r.FilterOps["price"][OpGte])
+	is.Equal("20", r.FilterOps["price"][OpLte])
+}
+
+func TestPaginationJSON(t *testing.T) {
+	is := assert.New(t)
+
+	b, _ := json.Marshal(Pagination{Limit: 10, Page: 1, TotalRows: 0, TotalPages: 0})
+	is.JSONEq(`{"limit":10,"page":1,"record_count":0,"total_pages":0,"rows":null}`, string(b))
+
+	b, _ = json.Marshal(Pagination{Limit: 10, Page: 2, HasMore: false, Mode: SimplePagination})
+	is.JSONEq(`{"limit":10,"page":2,"has_more":false,"rows":null}`, string(b))
+
+	b, _ = json.Marshal(Pagination{Limit: 10, HasMore: true, NextCursor: "abc", Mode: CursorPagination})
+	is.JSONEq(`{"limit":10,"has_more":true,"next_cursor":"abc","rows":null}`, string(b))
+}
@@ -12,13 +12,34 @@ import (
 
 // DBMock Helps with db testing, It generates a mock instance
 func DBMock(t *testing.T) (*sql.DB, *gorm.DB, sqlmock.Sqlmock) {
+	return openMock(func(d gorm.Dialector) gorm.Dialector { return d })
+}
+
+// NamedDBMock is DBMock with the dialector reporting another database name such as "mysql",
+// the generated SQL is still PostgreSQL's
+func NamedDBMock(t *testing.T, name string) (*sql.DB, *gorm.DB, sqlmock.Sqlmock) {
+	return openMock(func(d gorm.Dialector) gorm.Dialector { return namedDialector{d, name} })
+}
+
+// namedDialector reports another database name over a dialector
+type namedDialector struct {
+	gorm.Dialector
+	name string
+}
+
+func (d namedDialector) Name() string {
+	return d.name
+}
+
+// openMock opens gorm over a sqlmock connection with the wrapped postgres dialector
+func openMock(wrap func(gorm.Dialector) gorm.Dialector) (*sql.DB, *gorm.DB, sqlmock.Sqlmock) {
 	sqldb, mock, err := sqlmock.New()
 	if err != nil {
 		log.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
 	}
-	gormdb, err := gorm.Open(postgres.New(postgres.Config{
+	gormdb, err := gorm.Open(wrap(postgres.New(postgres.Config{
 		Conn: sqldb,
-	}), &gorm.Config{})
+	})), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("An error '%s' was not expected when opening gorm database", err)
 	}
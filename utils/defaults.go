@@ -1,6 +1,9 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // DefaultInt Checks if initial int is not empty and returns default
 func DefaultInt(val int, def int) int {
@@ -11,14 +14,33 @@ func DefaultInt(val int, def int) int {
 }
 
 // DefaultSort checks if initial value is not empty and returns a default value
+// Comma separated keys produce a multi-column sort, a "-" prefix sorts descending
 func DefaultSort(val string, def string) string {
-	if val == "" {
-		val = def
+	keys := SortKeys(val)
+	if len(keys) == 0 {
+		keys = SortKeys(def)
+	}
+
+	var cols []string
+	for _, key := range keys {
+		if key[0:1] == "-" {
+			cols = append(cols, fmt.Sprintf("%s DESC", key[1:]))
+		} else {
+			cols = append(cols, fmt.Sprintf("%s ASC", key))
+		}
 	}
-	if val[0:1] == "-" {
-		return fmt.Sprintf("%s DESC", val[1:])
+	return strings.Join(cols, ", ")
+}
+
+// SortKeys splits a comma separated sort value into its keys
+func SortKeys(val string) []string {
+	var keys []string
+	for _, key := range strings.Split(val, ",") {
+		if key = strings.TrimSpace(key); key != "" && key != "-" {
+			keys = append(keys, key)
+		}
 	}
-	return fmt.Sprintf("%s ASC", val)
+	return keys
 }
 
 // DefaultString checks if initial string is empty and returns default